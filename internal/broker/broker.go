@@ -0,0 +1,34 @@
+package broker
+
+import (
+	"context"
+
+	"matchmaker-nats/internal/entities"
+)
+
+// RequestHandler handles a matchmaking trigger. Returning an error hands the
+// trigger back to be delivered again later.
+type RequestHandler func(req *entities.MatchRequest) error
+
+// MatchHandler receives every formed match.
+type MatchHandler func(match *entities.Match)
+
+// Broker carries matchmaking triggers from the API to the workers and the
+// formed matches back.
+type Broker interface {
+	// PublishRequest queues a trigger for the worker of queueName.
+	PublishRequest(ctx context.Context, queueName string, req *entities.MatchRequest) error
+	// SubscribeRequests delivers the triggers of queueName to handler.
+	// Subscribers of the same queue form a group: each trigger goes to one
+	// of them.
+	SubscribeRequests(queueName string, handler RequestHandler) (Subscription, error)
+	// PublishMatch announces a formed match to every MatchHandler.
+	PublishMatch(ctx context.Context, match *entities.Match) error
+	SubscribeMatches(handler MatchHandler) (Subscription, error)
+}
+
+// Subscription stops a handler from receiving more messages. A message being
+// handled when it is called finishes.
+type Subscription interface {
+	Unsubscribe() error
+}
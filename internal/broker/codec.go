@@ -0,0 +1,98 @@
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/pkg/protos/gen"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// HeaderContentType names the payload encoding of request and match
+	// messages.
+	HeaderContentType = "Content-Type"
+
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+)
+
+// NewRequestMsg encodes a matchmaking request as protobuf for subject.
+func NewRequestMsg(subject string, req *entities.MatchRequest) (*nats.Msg, error) {
+	data, err := proto.Marshal(req.ToProto())
+	if err != nil {
+		return nil, err
+	}
+	return newMsg(subject, data), nil
+}
+
+// NewMatchMsg wraps a match already encoded with EncodeMatch for subject.
+func NewMatchMsg(subject string, data []byte) *nats.Msg {
+	return newMsg(subject, data)
+}
+
+// EncodeMatch returns the protobuf payload of a match message. It is encoded
+// once and shared by every subject the match is published on.
+func EncodeMatch(match *entities.Match) ([]byte, error) {
+	return proto.Marshal(match.ToProto())
+}
+
+// DecodeRequest reads a request in the encoding named by its Content-Type
+// header, so JSON publishers keep working.
+func DecodeRequest(header nats.Header, data []byte) (*entities.MatchRequest, error) {
+	var req entities.MatchRequest
+	if isJSON(header, data) {
+		if err := req.FromJSON(data); err != nil {
+			return nil, fmt.Errorf("decode JSON request: %w", err)
+		}
+		return &req, nil
+	}
+
+	var reqProto gen.MatchRequest
+	if err := proto.Unmarshal(data, &reqProto); err != nil {
+		return nil, fmt.Errorf("decode protobuf request: %w", err)
+	}
+	req.FromProto(&reqProto)
+	return &req, nil
+}
+
+// DecodeMatch reads a match in the encoding named by its Content-Type header.
+func DecodeMatch(header nats.Header, data []byte) (*entities.Match, error) {
+	var match entities.Match
+	if isJSON(header, data) {
+		if err := json.Unmarshal(data, &match); err != nil {
+			return nil, fmt.Errorf("decode JSON match: %w", err)
+		}
+		return &match, nil
+	}
+
+	var matchProto gen.Match
+	if err := proto.Unmarshal(data, &matchProto); err != nil {
+		return nil, fmt.Errorf("decode protobuf match: %w", err)
+	}
+	match.FromProto(&matchProto)
+	return &match, nil
+}
+
+func newMsg(subject string, data []byte) *nats.Msg {
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	msg.Header.Set(HeaderContentType, ContentTypeProtobuf)
+	return msg
+}
+
+// isJSON reports whether a payload is JSON. Messages without a Content-Type
+// predate the header: requests were JSON then, so a payload opening with '{'
+// is taken as JSON, anything else as protobuf.
+func isJSON(header nats.Header, data []byte) bool {
+	contentType := header.Get(HeaderContentType)
+	if contentType == "" {
+		trimmed := strings.TrimSpace(string(data))
+		return strings.HasPrefix(trimmed, "{")
+	}
+	return strings.HasPrefix(contentType, ContentTypeJSON)
+}
@@ -0,0 +1,70 @@
+package broker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// RequestStream persists matchmaking triggers of every queue until a
+	// worker acknowledges them.
+	RequestStream   = "MATCHMAKE_REQUESTS"
+	requestSubjects = RequestSubjectPrefix + ">"
+
+	// DeadLetterStream keeps triggers that failed MaxDeliver times.
+	DeadLetterStream        = "MATCHMAKE_DEADLETTER"
+	deadLetterSubjectPrefix = "matchmake.deadletter."
+
+	requestMaxAge    = time.Hour
+	deadLetterMaxAge = 7 * 24 * time.Hour
+
+	// Headers added to dead-lettered messages.
+	HeaderOriginalSubject = "Matchmake-Original-Subject"
+	HeaderDeliveries      = "Matchmake-Deliveries"
+	HeaderError           = "Matchmake-Error"
+)
+
+// DeadLetterSubject is where a queue's undeliverable triggers end up.
+func DeadLetterSubject(queueName string) string {
+	return deadLetterSubjectPrefix + queueName
+}
+
+// NewJetStream creates the JetStream context and makes sure both streams
+// exist with the expected configuration. API and workers both call it, so
+// whichever starts first sets the streams up.
+func NewJetStream(ctx context.Context, natsClient *nats.Conn) (jetstream.JetStream, error) {
+	js, err := jetstream.New(natsClient)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      RequestStream,
+		Subjects:  []string{requestSubjects},
+		Retention: jetstream.WorkQueuePolicy,
+		Storage:   jetstream.FileStorage,
+		MaxAge:    requestMaxAge,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[BROKER] JetStream stream %s ready for %s", RequestStream, requestSubjects)
+
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      DeadLetterStream,
+		Subjects:  []string{deadLetterSubjectPrefix + ">"},
+		Retention: jetstream.LimitsPolicy,
+		Storage:   jetstream.FileStorage,
+		MaxAge:    deadLetterMaxAge,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[BROKER] JetStream stream %s ready for %s>", DeadLetterStream, deadLetterSubjectPrefix)
+
+	return js, nil
+}
@@ -0,0 +1,153 @@
+package broker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+)
+
+// memoryQueueSize is how many triggers a queue buffers before PublishRequest
+// blocks.
+const memoryQueueSize = 1024
+
+// MemoryBroker passes triggers and matches over channels inside this process.
+// It keeps JetStream's delivery rules: a failed trigger is retried after
+// RedeliveryDelay up to MaxDeliver times, then dropped.
+type MemoryBroker struct {
+	mu       sync.Mutex
+	requests map[string]chan *entities.MatchRequest
+	matches  map[*memorySubscription]MatchHandler
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		requests: make(map[string]chan *entities.MatchRequest),
+		matches:  make(map[*memorySubscription]MatchHandler),
+	}
+}
+
+func (b *MemoryBroker) queue(queueName string) chan *entities.MatchRequest {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch, ok := b.requests[queueName]
+	if !ok {
+		ch = make(chan *entities.MatchRequest, memoryQueueSize)
+		b.requests[queueName] = ch
+	}
+	return ch
+}
+
+func (b *MemoryBroker) PublishRequest(ctx context.Context, queueName string, req *entities.MatchRequest) error {
+	copied := *req
+	select {
+	case b.queue(queueName) <- &copied:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SubscribeRequests starts a goroutine taking triggers off the queue's
+// channel; several subscribers of a queue compete for them.
+func (b *MemoryBroker) SubscribeRequests(queueName string, handler RequestHandler) (Subscription, error) {
+	ch := b.queue(queueName)
+	sub := newMemorySubscription()
+
+	go func() {
+		defer close(sub.stopped)
+		for {
+			select {
+			case req := <-ch:
+				b.handleRequest(sub, req, handler)
+			case <-sub.done:
+				return
+			}
+		}
+	}()
+
+	log.Printf("[BROKER] In-process subscriber receiving triggers of queue %s", queueName)
+	return sub, nil
+}
+
+func (b *MemoryBroker) handleRequest(sub *memorySubscription, req *entities.MatchRequest, handler RequestHandler) {
+	for delivered := 1; ; delivered++ {
+		err := handler(req)
+		if err == nil {
+			return
+		}
+		if delivered >= MaxDeliver {
+			log.Printf("[BROKER] Matchmaking failed on final delivery %d, dropping trigger: %v", delivered, err)
+			return
+		}
+
+		delay := RedeliveryDelay * time.Duration(delivered)
+		log.Printf("[BROKER] Matchmaking failed on delivery %d, retrying in %s: %v", delivered, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-sub.done:
+			return
+		}
+	}
+}
+
+// PublishMatch calls every match handler before returning.
+func (b *MemoryBroker) PublishMatch(ctx context.Context, match *entities.Match) error {
+	b.mu.Lock()
+	handlers := make([]MatchHandler, 0, len(b.matches))
+	for _, handler := range b.matches {
+		handlers = append(handlers, handler)
+	}
+	b.mu.Unlock()
+
+	for _, handler := range handlers {
+		copied := *match
+		handler(&copied)
+	}
+	return nil
+}
+
+func (b *MemoryBroker) SubscribeMatches(handler MatchHandler) (Subscription, error) {
+	sub := newMemorySubscription()
+	close(sub.stopped)
+
+	b.mu.Lock()
+	b.matches[sub] = handler
+	b.mu.Unlock()
+
+	sub.onStop = func() {
+		b.mu.Lock()
+		delete(b.matches, sub)
+		b.mu.Unlock()
+	}
+	return sub, nil
+}
+
+type memorySubscription struct {
+	once    sync.Once
+	done    chan struct{}
+	stopped chan struct{}
+	onStop  func()
+}
+
+func newMemorySubscription() *memorySubscription {
+	return &memorySubscription{
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Unsubscribe waits for a trigger being handled to finish.
+func (s *memorySubscription) Unsubscribe() error {
+	s.once.Do(func() {
+		close(s.done)
+		if s.onStop != nil {
+			s.onStop()
+		}
+	})
+	<-s.stopped
+	return nil
+}
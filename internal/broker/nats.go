@@ -0,0 +1,202 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	consumerPrefix = "matchmake-"
+
+	// AckWait is how long a worker may hold a trigger before JetStream
+	// redelivers it to another worker.
+	AckWait = 30 * time.Second
+	// MaxDeliver is how many times a trigger is attempted before it is moved
+	// to the dead-letter subject.
+	MaxDeliver = 5
+	// RedeliveryDelay is multiplied by the delivery count when a failed
+	// trigger is handed back.
+	RedeliveryDelay = 2 * time.Second
+)
+
+// NATSBroker sends triggers through the JetStream work queue and matches
+// over core NATS, so API and workers can run as separate replicas.
+type NATSBroker struct {
+	natsClient *nats.Conn
+	jetStream  jetstream.JetStream
+	// redeliveryDelay is RedeliveryDelay; tests shorten it.
+	redeliveryDelay time.Duration
+}
+
+func NewNATSBroker(natsClient *nats.Conn, jetStream jetstream.JetStream) *NATSBroker {
+	return &NATSBroker{
+		natsClient:      natsClient,
+		jetStream:       jetStream,
+		redeliveryDelay: RedeliveryDelay,
+	}
+}
+
+// ConsumerName is the durable consumer the workers of a queue share.
+func ConsumerName(queueName string) string {
+	return consumerPrefix + queueName
+}
+
+func (b *NATSBroker) PublishRequest(ctx context.Context, queueName string, req *entities.MatchRequest) error {
+	msg, err := NewRequestMsg(RequestSubject(queueName), req)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+	_, err = b.jetStream.PublishMsg(ctx, msg)
+	return err
+}
+
+// SubscribeRequests binds handler to the queue's durable pull consumer.
+// Replicas share the consumer, so each trigger is handled by one of them.
+func (b *NATSBroker) SubscribeRequests(queueName string, handler RequestHandler) (Subscription, error) {
+	durable := ConsumerName(queueName)
+	consumer, err := b.jetStream.CreateOrUpdateConsumer(context.Background(), RequestStream, jetstream.ConsumerConfig{
+		Durable:       durable,
+		FilterSubject: RequestSubject(queueName),
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       AckWait,
+		MaxDeliver:    MaxDeliver,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create consumer %s: %w", durable, err)
+	}
+
+	consumeContext, err := consumer.Consume(func(msg jetstream.Msg) {
+		b.handleRequest(queueName, msg, handler)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("consume from %s: %w", durable, err)
+	}
+
+	log.Printf("[BROKER] Consumer %s receiving triggers from %s", durable, RequestSubject(queueName))
+	return consumerSubscription{consumeContext}, nil
+}
+
+// handleRequest runs handler and acks the trigger. A failed pass is nak'ed
+// for a delayed redelivery until MaxDeliver is reached, after which the
+// trigger is dead-lettered.
+func (b *NATSBroker) handleRequest(queueName string, msg jetstream.Msg, handler RequestHandler) {
+	req, err := DecodeRequest(msg.Headers(), msg.Data())
+	if err == nil && req.Queue != "" && req.Queue != queueName {
+		err = fmt.Errorf("request for queue %q delivered to queue %s", req.Queue, queueName)
+	}
+	if err != nil {
+		// Redelivering won't make the payload readable.
+		log.Printf("[BROKER] Rejecting malformed matchmaking request: %v", err)
+		b.deadLetter(queueName, msg, 1, err)
+		if err := msg.Term(); err != nil {
+			log.Printf("[BROKER] Failed to terminate NATS message: %v", err)
+		}
+		return
+	}
+
+	err = handler(req)
+	if err == nil {
+		if err := msg.Ack(); err != nil {
+			log.Printf("[BROKER] Failed to acknowledge NATS message: %v", err)
+			return
+		}
+		log.Printf("[BROKER] NATS message acknowledged")
+		return
+	}
+
+	var delivered uint64 = 1
+	if meta, metaErr := msg.Metadata(); metaErr == nil {
+		delivered = meta.NumDelivered
+	}
+
+	if delivered >= MaxDeliver {
+		log.Printf("[BROKER] Matchmaking failed on final delivery %d: %v", delivered, err)
+		b.deadLetter(queueName, msg, delivered, err)
+		if err := msg.Term(); err != nil {
+			log.Printf("[BROKER] Failed to terminate NATS message: %v", err)
+		}
+		return
+	}
+
+	delay := b.redeliveryDelay * time.Duration(delivered)
+	log.Printf("[BROKER] Matchmaking failed on delivery %d, redelivering in %s: %v", delivered, delay, err)
+	if err := msg.NakWithDelay(delay); err != nil {
+		log.Printf("[BROKER] Failed to nak NATS message: %v", err)
+	}
+}
+
+// deadLetter republishes a trigger that kept failing so it can be inspected
+// and replayed by hand.
+func (b *NATSBroker) deadLetter(queueName string, msg jetstream.Msg, delivered uint64, cause error) {
+	deadMsg := nats.NewMsg(DeadLetterSubject(queueName))
+	deadMsg.Data = msg.Data()
+	for key, values := range msg.Headers() {
+		for _, value := range values {
+			deadMsg.Header.Add(key, value)
+		}
+	}
+	deadMsg.Header.Set(HeaderOriginalSubject, msg.Subject())
+	deadMsg.Header.Set(HeaderDeliveries, strconv.FormatUint(delivered, 10))
+	deadMsg.Header.Set(HeaderError, cause.Error())
+
+	if _, err := b.jetStream.PublishMsg(context.Background(), deadMsg); err != nil {
+		log.Printf("[BROKER] Failed to dead-letter NATS message: %v", err)
+		return
+	}
+	log.Printf("[BROKER] NATS message moved to dead-letter subject %s", deadMsg.Subject)
+}
+
+// PublishMatch emits the match on its own subject and on the matched subject
+// of every player in it.
+func (b *NATSBroker) PublishMatch(ctx context.Context, match *entities.Match) error {
+	data, err := EncodeMatch(match)
+	if err != nil {
+		return fmt.Errorf("encode match: %w", err)
+	}
+
+	// Players are notified even if the match subject failed.
+	err = b.natsClient.PublishMsg(NewMatchMsg(MatchSubject(match.MatchID), data))
+
+	for _, player := range match.Players {
+		if err := b.natsClient.PublishMsg(NewMatchMsg(PlayerMatchedSubject(player.ID), data)); err != nil {
+			log.Printf("[BROKER] Failed to notify player %s of match %s: %v", player.ID, match.MatchID, err)
+		}
+	}
+	return err
+}
+
+// SubscribeMatches listens on MatchWildcard; every API instance gets every
+// match.
+func (b *NATSBroker) SubscribeMatches(handler MatchHandler) (Subscription, error) {
+	sub, err := b.natsClient.Subscribe(MatchWildcard, func(msg *nats.Msg) {
+		match, err := DecodeMatch(msg.Header, msg.Data)
+		if err != nil {
+			log.Printf("[BROKER] Failed to decode match from %s: %v", msg.Subject, err)
+			return
+		}
+		handler(match)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[BROKER] Listening for matches on %s", MatchWildcard)
+	return sub, nil
+}
+
+type consumerSubscription struct {
+	consumeContext jetstream.ConsumeContext
+}
+
+func (s consumerSubscription) Unsubscribe() error {
+	s.consumeContext.Stop()
+	return nil
+}
@@ -0,0 +1,203 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const testQueue = "test"
+
+// newTestBroker starts an embedded JetStream server and returns a broker
+// connected to it, with redeliveries sped up.
+func newTestBroker(t *testing.T) (*NATSBroker, jetstream.JetStream) {
+	t.Helper()
+
+	ns, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("start nats-server: %v", err)
+	}
+	go ns.Start()
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats-server not ready")
+	}
+	t.Cleanup(ns.Shutdown)
+
+	nc, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(nc.Close)
+
+	js, err := NewJetStream(context.Background(), nc)
+	if err != nil {
+		t.Fatalf("set up streams: %v", err)
+	}
+
+	b := NewNATSBroker(nc, js)
+	b.redeliveryDelay = 10 * time.Millisecond
+	return b, js
+}
+
+// subscribe counts the deliveries of triggers to a handler failing the
+// first fail of them.
+func subscribe(t *testing.T, b *NATSBroker, fail int32) *atomic.Int32 {
+	t.Helper()
+
+	var delivered atomic.Int32
+	sub, err := b.SubscribeRequests(testQueue, func(req *entities.MatchRequest) error {
+		if delivered.Add(1) <= fail {
+			return errors.New("pass failed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	t.Cleanup(func() { sub.Unsubscribe() })
+	return &delivered
+}
+
+func publish(t *testing.T, b *NATSBroker) {
+	t.Helper()
+
+	req := &entities.MatchRequest{Player: entities.Player{ID: "player-1"}, Queue: testQueue, TicketID: "ticket-1"}
+	if err := b.PublishRequest(context.Background(), testQueue, req); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+}
+
+// eventually polls cond until it holds or a few seconds passed.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func streamMsgs(t *testing.T, js jetstream.JetStream, name string) uint64 {
+	t.Helper()
+
+	stream, err := js.Stream(context.Background(), name)
+	if err != nil {
+		t.Fatalf("stream %s: %v", name, err)
+	}
+	info, err := stream.Info(context.Background())
+	if err != nil {
+		t.Fatalf("stream %s info: %v", name, err)
+	}
+	return info.State.Msgs
+}
+
+func TestNATSBrokerAcksHandledTrigger(t *testing.T) {
+	b, js := newTestBroker(t)
+	delivered := subscribe(t, b, 0)
+
+	publish(t, b)
+
+	eventually(t, "the trigger to be acked", func() bool {
+		return delivered.Load() == 1 && streamMsgs(t, js, RequestStream) == 0
+	})
+	time.Sleep(100 * time.Millisecond)
+	if n := delivered.Load(); n != 1 {
+		t.Errorf("delivered %d times, want 1", n)
+	}
+	if n := streamMsgs(t, js, DeadLetterStream); n != 0 {
+		t.Errorf("%d dead letters, want 0", n)
+	}
+}
+
+func TestNATSBrokerRedeliversFailedTrigger(t *testing.T) {
+	b, js := newTestBroker(t)
+	delivered := subscribe(t, b, 2)
+
+	publish(t, b)
+
+	eventually(t, "the third delivery to be acked", func() bool {
+		return delivered.Load() == 3 && streamMsgs(t, js, RequestStream) == 0
+	})
+	time.Sleep(100 * time.Millisecond)
+	if n := delivered.Load(); n != 3 {
+		t.Errorf("delivered %d times, want 3", n)
+	}
+	if n := streamMsgs(t, js, DeadLetterStream); n != 0 {
+		t.Errorf("%d dead letters, want 0", n)
+	}
+}
+
+func TestNATSBrokerDeadLettersAfterMaxDeliver(t *testing.T) {
+	b, js := newTestBroker(t)
+	delivered := subscribe(t, b, MaxDeliver)
+
+	publish(t, b)
+
+	eventually(t, "the trigger to be dead-lettered", func() bool {
+		return streamMsgs(t, js, DeadLetterStream) == 1
+	})
+	if n := delivered.Load(); n != MaxDeliver {
+		t.Errorf("delivered %d times, want %d", n, MaxDeliver)
+	}
+	eventually(t, "the trigger to leave the work queue", func() bool {
+		return streamMsgs(t, js, RequestStream) == 0
+	})
+
+	stream, err := js.Stream(context.Background(), DeadLetterStream)
+	if err != nil {
+		t.Fatalf("dead-letter stream: %v", err)
+	}
+	msg, err := stream.GetLastMsgForSubject(context.Background(), DeadLetterSubject(testQueue))
+	if err != nil {
+		t.Fatalf("dead letter: %v", err)
+	}
+	if got := msg.Header.Get(HeaderOriginalSubject); got != RequestSubject(testQueue) {
+		t.Errorf("original subject = %q, want %q", got, RequestSubject(testQueue))
+	}
+	if got := msg.Header.Get(HeaderDeliveries); got != strconv.Itoa(MaxDeliver) {
+		t.Errorf("deliveries = %q, want %d", got, MaxDeliver)
+	}
+	if got := msg.Header.Get(HeaderError); got != "pass failed" {
+		t.Errorf("error = %q, want %q", got, "pass failed")
+	}
+
+	req, err := DecodeRequest(msg.Header, msg.Data)
+	if err != nil || req.TicketID != "ticket-1" {
+		t.Errorf("dead-lettered request = %+v, %v; want ticket-1", req, err)
+	}
+}
+
+func TestNATSBrokerDeadLettersMalformedTrigger(t *testing.T) {
+	b, js := newTestBroker(t)
+	delivered := subscribe(t, b, 0)
+
+	if _, err := js.Publish(context.Background(), RequestSubject(testQueue), []byte("not a request")); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	eventually(t, "the trigger to be dead-lettered", func() bool {
+		return streamMsgs(t, js, DeadLetterStream) == 1 && streamMsgs(t, js, RequestStream) == 0
+	})
+	if n := delivered.Load(); n != 0 {
+		t.Errorf("handler called %d times, want 0", n)
+	}
+}
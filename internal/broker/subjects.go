@@ -0,0 +1,42 @@
+package broker
+
+import "strings"
+
+const (
+	// RequestSubjectPrefix is followed by the queue name; matchmaking
+	// triggers of the queue are published there.
+	RequestSubjectPrefix = "matchmake.request."
+	// MatchSubjectPrefix is followed by the match ID; every formed match is
+	// published there encoded as gen.Match.
+	MatchSubjectPrefix = "matchmake.match."
+	// PlayerSubjectPrefix is followed by "<player_id>.matched" so clients can
+	// subscribe to their own results only.
+	PlayerSubjectPrefix = "matchmake.player."
+
+	playerMatchedSuffix = ".matched"
+
+	// PlayerMatchedWildcard matches the matched subject of every player.
+	PlayerMatchedWildcard = PlayerSubjectPrefix + "*" + playerMatchedSuffix
+	// MatchWildcard matches the subject of every match.
+	MatchWildcard = MatchSubjectPrefix + "*"
+)
+
+// RequestSubject returns the NATS subject a queue's triggers are published on.
+func RequestSubject(queueName string) string {
+	return RequestSubjectPrefix + queueName
+}
+
+// MatchSubject returns the NATS subject a match is published on.
+func MatchSubject(matchID string) string {
+	return MatchSubjectPrefix + matchID
+}
+
+// PlayerMatchedSubject returns the NATS subject a player's matches are published on.
+func PlayerMatchedSubject(playerID string) string {
+	return PlayerSubjectPrefix + playerID + playerMatchedSuffix
+}
+
+// PlayerFromMatchedSubject extracts the player ID from a PlayerMatchedSubject.
+func PlayerFromMatchedSubject(subject string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(subject, PlayerSubjectPrefix), playerMatchedSuffix)
+}
@@ -0,0 +1,62 @@
+package entities
+
+import (
+	"strconv"
+	"time"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Delivery records how pushing a match to its queue's webhook went.
+type Delivery struct {
+	MatchID  string         `json:"match_id"`
+	URL      string         `json:"url"`
+	Status   DeliveryStatus `json:"status"`
+	Attempts int            `json:"attempts"`
+	// StatusCode and LastError describe the latest attempt.
+	StatusCode int       `json:"status_code,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (d *Delivery) ToHash() map[string]interface{} {
+	return map[string]interface{}{
+		"match_id":    d.MatchID,
+		"url":         d.URL,
+		"status":      string(d.Status),
+		"attempts":    d.Attempts,
+		"status_code": d.StatusCode,
+		"last_error":  d.LastError,
+		"updated_at":  d.UpdatedAt.Unix(),
+	}
+}
+
+func (d *Delivery) FromHash(hash map[string]string) error {
+	attempts, err := hashInt(hash, "attempts")
+	if err != nil {
+		return err
+	}
+	statusCode, err := hashInt(hash, "status_code")
+	if err != nil {
+		return err
+	}
+	updatedAt, err := strconv.ParseInt(hash["updated_at"], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	d.MatchID = hash["match_id"]
+	d.URL = hash["url"]
+	d.Status = DeliveryStatus(hash["status"])
+	d.Attempts = attempts
+	d.StatusCode = statusCode
+	d.LastError = hash["last_error"]
+	d.UpdatedAt = time.Unix(updatedAt, 0)
+	return nil
+}
@@ -2,6 +2,7 @@ package entities
 
 import (
 	"encoding/json"
+	"strconv"
 	"time"
 
 	"matchmaker-nats/pkg/protos/gen"
@@ -10,29 +11,67 @@ import (
 type Player struct {
 	ID   string `json:"id"`
 	Ping int    `json:"ping"`
+	MMR  int    `json:"mmr"`
+	// Pings maps a region name to the player's latency to it in ms.
+	Pings map[string]int `json:"pings,omitempty"`
 }
 
+// MatchRequest queues Player alone or, when Party is set, as the leader of a
+// party that is matched as one unit. Queue selects the game mode. TicketID is
+// filled in once the request is queued. RequestID is an optional idempotency
+// key chosen by the client.
 type MatchRequest struct {
-	Player Player `json:"player"`
+	Player    Player   `json:"player"`
+	Party     []Player `json:"party,omitempty"`
+	Queue     string   `json:"queue,omitempty"`
+	TicketID  string   `json:"ticket_id,omitempty"`
+	RequestID string   `json:"request_id,omitempty"`
+}
+
+// Team is one side of a match; MMR is the average rating of its players.
+type Team struct {
+	Players []Player `json:"players"`
+	MMR     int      `json:"mmr"`
 }
 
 type Match struct {
 	MatchID   string    `json:"match_id"`
 	Players   []Player  `json:"players"`
 	CreatedAt time.Time `json:"created_at"`
+	Region    string    `json:"region,omitempty"`
+	Teams     []Team    `json:"teams,omitempty"`
+	Queue     string    `json:"queue,omitempty"`
 }
 
 // Player serialization methods
 func (p *Player) ToProto() *gen.Player {
+	var pings map[string]int32
+	if len(p.Pings) > 0 {
+		pings = make(map[string]int32, len(p.Pings))
+		for region, ping := range p.Pings {
+			pings[region] = int32(ping)
+		}
+	}
+
 	return &gen.Player{
-		Id:   p.ID,
-		Ping: int32(p.Ping),
+		Id:    p.ID,
+		Ping:  int32(p.Ping),
+		Mmr:   int32(p.MMR),
+		Pings: pings,
 	}
 }
 
 func (p *Player) FromProto(proto *gen.Player) {
 	p.ID = proto.Id
 	p.Ping = int(proto.Ping)
+	p.MMR = int(proto.Mmr)
+	p.Pings = nil
+	if len(proto.Pings) > 0 {
+		p.Pings = make(map[string]int, len(proto.Pings))
+		for region, ping := range proto.Pings {
+			p.Pings[region] = int(ping)
+		}
+	}
 }
 
 func (p *Player) ToJSON() ([]byte, error) {
@@ -45,13 +84,35 @@ func (p *Player) FromJSON(data []byte) error {
 
 // MatchRequest serialization methods
 func (mr *MatchRequest) ToProto() *gen.MatchRequest {
+	party := make([]*gen.Player, len(mr.Party))
+	for i, member := range mr.Party {
+		party[i] = member.ToProto()
+	}
+
 	return &gen.MatchRequest{
-		Player: mr.Player.ToProto(),
+		Player:    mr.Player.ToProto(),
+		Party:     party,
+		Queue:     mr.Queue,
+		TicketId:  mr.TicketID,
+		RequestId: mr.RequestID,
 	}
 }
 
 func (mr *MatchRequest) FromProto(proto *gen.MatchRequest) {
-	mr.Player.FromProto(proto.Player)
+	mr.Player = Player{}
+	if proto.Player != nil {
+		mr.Player.FromProto(proto.Player)
+	}
+	mr.Queue = proto.Queue
+	mr.TicketID = proto.TicketId
+	mr.RequestID = proto.RequestId
+	mr.Party = nil
+	if len(proto.Party) > 0 {
+		mr.Party = make([]Player, len(proto.Party))
+		for i, memberProto := range proto.Party {
+			mr.Party[i].FromProto(memberProto)
+		}
+	}
 }
 
 func (mr *MatchRequest) ToJSON() ([]byte, error) {
@@ -62,6 +123,27 @@ func (mr *MatchRequest) FromJSON(data []byte) error {
 	return json.Unmarshal(data, mr)
 }
 
+// Team serialization methods
+func (t *Team) ToProto() *gen.Team {
+	players := make([]*gen.Player, len(t.Players))
+	for i, player := range t.Players {
+		players[i] = player.ToProto()
+	}
+
+	return &gen.Team{
+		Players: players,
+		Mmr:     int32(t.MMR),
+	}
+}
+
+func (t *Team) FromProto(proto *gen.Team) {
+	t.Players = make([]Player, len(proto.Players))
+	for i, playerProto := range proto.Players {
+		t.Players[i].FromProto(playerProto)
+	}
+	t.MMR = int(proto.Mmr)
+}
+
 // Match serialization methods
 func (m *Match) ToProto() *gen.Match {
 	players := make([]*gen.Player, len(m.Players))
@@ -69,10 +151,18 @@ func (m *Match) ToProto() *gen.Match {
 		players[i] = player.ToProto()
 	}
 
+	var teams []*gen.Team
+	for _, team := range m.Teams {
+		teams = append(teams, team.ToProto())
+	}
+
 	return &gen.Match{
 		MatchId:   m.MatchID,
 		Players:   players,
 		CreatedAt: m.CreatedAt.Unix(),
+		Region:    m.Region,
+		Teams:     teams,
+		Queue:     m.Queue,
 	}
 }
 
@@ -83,6 +173,14 @@ func (m *Match) FromProto(proto *gen.Match) {
 		m.Players[i].FromProto(playerProto)
 	}
 	m.CreatedAt = time.Unix(proto.CreatedAt, 0)
+	m.Region = proto.Region
+	m.Queue = proto.Queue
+	m.Teams = nil
+	for _, teamProto := range proto.Teams {
+		var team Team
+		team.FromProto(teamProto)
+		m.Teams = append(m.Teams, team)
+	}
 }
 
 func (m *Match) ToJSON() ([]byte, error) {
@@ -92,3 +190,49 @@ func (m *Match) ToJSON() ([]byte, error) {
 func (m *Match) FromJSON(data []byte) error {
 	return json.Unmarshal(data, m)
 }
+
+func (m *Match) ToHash() (map[string]interface{}, error) {
+	players, err := json.Marshal(m.Players)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := json.Marshal(m.Teams)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"match_id":   m.MatchID,
+		"queue":      m.Queue,
+		"region":     m.Region,
+		"players":    string(players),
+		"teams":      string(teams),
+		"created_at": m.CreatedAt.Unix(),
+	}, nil
+}
+
+func (m *Match) FromHash(hash map[string]string) error {
+	createdAt, err := strconv.ParseInt(hash["created_at"], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	var players []Player
+	if err := json.Unmarshal([]byte(hash["players"]), &players); err != nil {
+		return err
+	}
+	var teams []Team
+	if value := hash["teams"]; value != "" {
+		if err := json.Unmarshal([]byte(value), &teams); err != nil {
+			return err
+		}
+	}
+
+	m.MatchID = hash["match_id"]
+	m.Queue = hash["queue"]
+	m.Region = hash["region"]
+	m.Players = players
+	m.Teams = teams
+	m.CreatedAt = time.Unix(createdAt, 0)
+	return nil
+}
@@ -0,0 +1,43 @@
+package entities
+
+import "matchmaker-nats/pkg/protos/gen"
+
+type TicketEventType string
+
+const (
+	EventQueued    TicketEventType = "queued"
+	EventPosition  TicketEventType = "position"
+	EventMatched   TicketEventType = "matched"
+	EventCancelled TicketEventType = "cancelled"
+	EventExpired   TicketEventType = "expired"
+	EventError     TicketEventType = "error"
+)
+
+// TicketEvent is pushed to clients waiting on a ticket (WebSocket, SSE, ...).
+type TicketEvent struct {
+	Type     TicketEventType `json:"type"`
+	Ticket   *Ticket         `json:"ticket,omitempty"`
+	Position int64           `json:"position,omitempty"`
+	Match    *Match          `json:"match,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// Final reports whether no further events follow for the ticket.
+func (e TicketEvent) Final() bool {
+	return e.Type != EventQueued && e.Type != EventPosition
+}
+
+func (e *TicketEvent) ToProto() *gen.TicketEvent {
+	event := &gen.TicketEvent{
+		Type:     string(e.Type),
+		Position: e.Position,
+		Error:    e.Error,
+	}
+	if e.Ticket != nil {
+		event.Ticket = e.Ticket.ToProto()
+	}
+	if e.Match != nil {
+		event.Match = e.Match.ToProto()
+	}
+	return event
+}
@@ -0,0 +1,208 @@
+package entities
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"matchmaker-nats/pkg/protos/gen"
+)
+
+type TicketStatus string
+
+const (
+	TicketQueued    TicketStatus = "queued"
+	TicketMatched   TicketStatus = "matched"
+	TicketCancelled TicketStatus = "cancelled"
+	TicketExpired   TicketStatus = "expired"
+)
+
+// Terminal reports whether the ticket has left the queue for good.
+func (s TicketStatus) Terminal() bool {
+	return s == TicketMatched || s == TicketCancelled || s == TicketExpired
+}
+
+// Ticket is one entry in the pool. Player is the one who queued; Members are
+// the rest of their party, if any.
+type Ticket struct {
+	ID        string       `json:"id"`
+	Player    Player       `json:"player"`
+	Members   []Player     `json:"members,omitempty"`
+	Queue     string       `json:"queue"`
+	Status    TicketStatus `json:"status"`
+	MatchID   string       `json:"match_id,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// Players returns everyone on the ticket, the leader first.
+func (t *Ticket) Players() []Player {
+	players := make([]Player, 0, 1+len(t.Members))
+	players = append(players, t.Player)
+	return append(players, t.Members...)
+}
+
+// Size is the number of match slots the ticket takes.
+func (t *Ticket) Size() int {
+	return 1 + len(t.Members)
+}
+
+// MMR is the average rating of everyone on the ticket.
+func (t *Ticket) MMR() int {
+	total := 0
+	for _, player := range t.Players() {
+		total += player.MMR
+	}
+	return total / t.Size()
+}
+
+// Pings returns the worst ping of the party for every region all reporting
+// players share. Players without pings don't restrict the regions; the
+// result is empty when nobody reported any.
+func (t *Ticket) Pings() map[string]int {
+	var pings map[string]int
+	for _, player := range t.Players() {
+		if len(player.Pings) == 0 {
+			continue
+		}
+		if pings == nil {
+			pings = make(map[string]int, len(player.Pings))
+			for region, ping := range player.Pings {
+				pings[region] = ping
+			}
+			continue
+		}
+		for region, worst := range pings {
+			ping, ok := player.Pings[region]
+			if !ok {
+				delete(pings, region)
+			} else if ping > worst {
+				pings[region] = ping
+			}
+		}
+	}
+	return pings
+}
+
+// Ticket serialization methods
+func (t *Ticket) ToProto() *gen.Ticket {
+	var members []*gen.Player
+	for _, member := range t.Members {
+		members = append(members, member.ToProto())
+	}
+
+	return &gen.Ticket{
+		Id:        t.ID,
+		Player:    t.Player.ToProto(),
+		Members:   members,
+		Queue:     t.Queue,
+		Status:    string(t.Status),
+		MatchId:   t.MatchID,
+		CreatedAt: t.CreatedAt.Unix(),
+		UpdatedAt: t.UpdatedAt.Unix(),
+	}
+}
+
+func (t *Ticket) FromProto(proto *gen.Ticket) {
+	t.ID = proto.Id
+	t.Player = Player{}
+	if proto.Player != nil {
+		t.Player.FromProto(proto.Player)
+	}
+	t.Members = nil
+	for _, memberProto := range proto.Members {
+		var member Player
+		member.FromProto(memberProto)
+		t.Members = append(t.Members, member)
+	}
+	t.Queue = proto.Queue
+	t.Status = TicketStatus(proto.Status)
+	t.MatchID = proto.MatchId
+	t.CreatedAt = time.Unix(proto.CreatedAt, 0)
+	t.UpdatedAt = time.Unix(proto.UpdatedAt, 0)
+}
+
+func (t *Ticket) ToHash() (map[string]interface{}, error) {
+	pings, err := json.Marshal(t.Player.Pings)
+	if err != nil {
+		return nil, err
+	}
+	members, err := json.Marshal(t.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"id":         t.ID,
+		"player_id":  t.Player.ID,
+		"ping":       t.Player.Ping,
+		"mmr":        t.Player.MMR,
+		"pings":      string(pings),
+		"members":    string(members),
+		"queue":      t.Queue,
+		"status":     string(t.Status),
+		"match_id":   t.MatchID,
+		"created_at": t.CreatedAt.Unix(),
+		"updated_at": t.UpdatedAt.Unix(),
+	}, nil
+}
+
+func (t *Ticket) FromHash(hash map[string]string) error {
+	ping, err := hashInt(hash, "ping")
+	if err != nil {
+		return err
+	}
+	mmr, err := hashInt(hash, "mmr")
+	if err != nil {
+		return err
+	}
+	createdAt, err := strconv.ParseInt(hash["created_at"], 10, 64)
+	if err != nil {
+		return err
+	}
+	updatedAt, err := strconv.ParseInt(hash["updated_at"], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	var pings map[string]int
+	if value := hash["pings"]; value != "" {
+		if err := json.Unmarshal([]byte(value), &pings); err != nil {
+			return err
+		}
+	}
+	var members []Player
+	if value := hash["members"]; value != "" {
+		if err := json.Unmarshal([]byte(value), &members); err != nil {
+			return err
+		}
+	}
+
+	t.ID = hash["id"]
+	t.Player = Player{ID: hash["player_id"], Ping: ping, MMR: mmr, Pings: pings}
+	t.Members = members
+	t.Queue = hash["queue"]
+	t.Status = TicketStatus(hash["status"])
+	t.MatchID = hash["match_id"]
+	t.CreatedAt = time.Unix(createdAt, 0)
+	t.UpdatedAt = time.Unix(updatedAt, 0)
+	return nil
+}
+
+func (t *Ticket) ToJSON() ([]byte, error) {
+	return json.Marshal(t)
+}
+
+func (t *Ticket) FromJSON(data []byte) error {
+	return json.Unmarshal(data, t)
+}
+
+// hashInt reads an optional integer field; tickets written before the field
+// existed simply don't have it.
+func hashInt(hash map[string]string, field string) (int, error) {
+	value, ok := hash[field]
+	if !ok || value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"matchmaker-nats/internal/broker"
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+	"matchmaker-nats/internal/store"
+)
+
+func TestCancel(t *testing.T) {
+	ctx := context.Background()
+	config := queue.Default("test")
+
+	newHandler := func(t *testing.T) (*matchmakeHandler, *entities.Ticket) {
+		stores := store.NewMemoryStores()
+		ticket := &entities.Ticket{ID: "ticket-1", Player: entities.Player{ID: "player-1"}, Queue: config.Name, Status: entities.TicketQueued, CreatedAt: time.Now()}
+		if _, _, err := stores.Pool.Enqueue(ctx, ticket, ""); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		return NewMatchmakeHandler(broker.NewMemoryBroker(), stores, nil, []queue.Config{config}), ticket
+	}
+	cancelled := func(t *testing.T, h *matchmakeHandler) {
+		t.Helper()
+		ticket, err := h.tickets.Get(ctx, "ticket-1")
+		if err != nil || ticket.Status != entities.TicketCancelled {
+			t.Errorf("ticket = %+v, %v; want cancelled", ticket, err)
+		}
+		if size, _ := h.tickets.Size(ctx, config.Name); size != 0 {
+			t.Errorf("pool size = %d, want 0", size)
+		}
+		if recovered, _ := h.tickets.RecoverClaims(ctx, config.Name, time.Now().Add(time.Hour)); len(recovered) != 0 {
+			t.Errorf("claims left behind = %v, want none", recovered)
+		}
+	}
+
+	t.Run("queued", func(t *testing.T) {
+		h, ticket := newHandler(t)
+		if removed, err := h.cancel(ctx, ticket); err != nil || !removed {
+			t.Fatalf("cancel = %v, %v; want true", removed, err)
+		}
+		cancelled(t, h)
+	})
+
+	t.Run("claimed by a pass", func(t *testing.T) {
+		h, ticket := newHandler(t)
+		if _, err := h.tickets.ClaimBatch(ctx, config.Name, 10); err != nil {
+			t.Fatalf("claim: %v", err)
+		}
+		if removed, err := h.cancel(ctx, ticket); err != nil || !removed {
+			t.Fatalf("cancel = %v, %v; want true", removed, err)
+		}
+		cancelled(t, h)
+	})
+
+	t.Run("matched", func(t *testing.T) {
+		h, ticket := newHandler(t)
+		if err := h.tickets.SetMatched(ctx, []*entities.Ticket{ticket}, "match_1"); err != nil {
+			t.Fatalf("match: %v", err)
+		}
+		if removed, err := h.cancel(ctx, ticket); err != nil || removed {
+			t.Fatalf("cancel = %v, %v; want false", removed, err)
+		}
+		if got, _ := h.tickets.Get(ctx, "ticket-1"); got.Status != entities.TicketMatched {
+			t.Errorf("status = %s, want matched", got.Status)
+		}
+	})
+}
+
+func TestCancelAfterGrace(t *testing.T) {
+	ctx := context.Background()
+	config := queue.Default("test")
+
+	newHandler := func(t *testing.T) (*matchmakeHandler, *entities.Ticket) {
+		stores := store.NewMemoryStores()
+		ticket := &entities.Ticket{ID: "ticket-1", Player: entities.Player{ID: "player-1"}, Queue: config.Name, Status: entities.TicketQueued, CreatedAt: time.Now()}
+		if _, _, err := stores.Pool.Enqueue(ctx, ticket, ""); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		h := NewMatchmakeHandler(broker.NewMemoryBroker(), stores, nil, []queue.Config{config})
+		h.reconnectGrace = 10 * time.Millisecond
+		return h, ticket
+	}
+	status := func(t *testing.T, h *matchmakeHandler) entities.TicketStatus {
+		t.Helper()
+		ticket, err := h.tickets.Get(ctx, "ticket-1")
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		return ticket.Status
+	}
+
+	t.Run("client gone", func(t *testing.T) {
+		h, ticket := newHandler(t)
+		h.socketOpened(ticket.ID)
+		if !h.socketClosed(ticket.ID) {
+			t.Fatal("last socket not reported as closed")
+		}
+		h.cancelAfterGrace(ticket)
+		if got := status(t, h); got != entities.TicketCancelled {
+			t.Errorf("status = %s, want cancelled", got)
+		}
+	})
+
+	t.Run("client reconnected", func(t *testing.T) {
+		h, ticket := newHandler(t)
+		h.socketOpened(ticket.ID)
+		h.socketClosed(ticket.ID)
+		h.socketOpened(ticket.ID)
+		h.cancelAfterGrace(ticket)
+		if got := status(t, h); got != entities.TicketQueued {
+			t.Errorf("status = %s, want queued", got)
+		}
+	})
+
+	t.Run("another socket still open", func(t *testing.T) {
+		h, ticket := newHandler(t)
+		h.socketOpened(ticket.ID)
+		h.socketOpened(ticket.ID)
+		if h.socketClosed(ticket.ID) {
+			t.Error("socket reported as the last one while another is open")
+		}
+	})
+}
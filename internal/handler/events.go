@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// MaxTicketWait caps the ?wait= long-poll of GetTicket.
+	MaxTicketWait = 60 * time.Second
+	// sseKeepAlive is how often an idle event stream sends a comment, which
+	// also detects clients that went away.
+	sseKeepAlive = 15 * time.Second
+)
+
+// errStopWatching ends a watch once the caller got what it waited for.
+var errStopWatching = errors.New("stop watching")
+
+// TicketEvents streams the ticket's events as Server-Sent Events until it
+// leaves the queue. Unlike the WebSocket, disconnecting doesn't cancel it.
+func (h *matchmakeHandler) TicketEvents(c *fiber.Ctx) error {
+	ticketID := c.Params("ticket")
+
+	log.Printf("[HANDLER] Received event stream request for ticket %s", ticketID)
+
+	ticket, err := h.tickets.Get(c.Context(), ticketID)
+	if errors.Is(err, store.ErrTicketNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ticket not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[HANDLER] Failed to load ticket %s: %v", ticketID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load ticket",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var mu sync.Mutex
+		write := func(chunk string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if _, err := w.WriteString(chunk); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+		send := func(event entities.TicketEvent) error {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			return write(fmt.Sprintf("event: %s\ndata: %s\n\n", event.Type, data))
+		}
+
+		go func() {
+			ticker := time.NewTicker(sseKeepAlive)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := write(": keep-alive\n\n"); err != nil {
+						cancel()
+						return
+					}
+				}
+			}
+		}()
+
+		if err := h.streamTicket(ctx, ticket, send); err != nil {
+			log.Printf("[HANDLER] Event stream for ticket %s closed: %v", ticket.ID, err)
+			send(entities.TicketEvent{Type: entities.EventError, Error: "Failed to watch ticket"})
+			return
+		}
+		log.Printf("[HANDLER] Ticket %s left the queue as %s, closing event stream", ticket.ID, ticket.Status)
+	})
+
+	return nil
+}
+
+// streamTicket sends the ticket's current state, then its events until it
+// leaves the queue.
+func (h *matchmakeHandler) streamTicket(ctx context.Context, ticket *entities.Ticket, send func(entities.TicketEvent) error) error {
+	matches, unsubscribe := h.notifier.Subscribe(ticket.Player.ID)
+	defer unsubscribe()
+
+	// Read the state again now that match events are received, so an outcome
+	// reached in between isn't missed.
+	event, err := h.ticketEvent(ctx, ticket.ID)
+	if err != nil {
+		return err
+	}
+	*ticket = *event.Ticket
+	if err := send(event); err != nil || event.Final() {
+		return err
+	}
+
+	return h.watchTicket(ctx, ticket, matches, send)
+}
+
+// waitForTicket blocks until the queued ticket leaves the queue or wait
+// elapses, updating ticket in place.
+func (h *matchmakeHandler) waitForTicket(ctx context.Context, ticket *entities.Ticket, wait time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	matches, unsubscribe := h.notifier.Subscribe(ticket.Player.ID)
+	defer unsubscribe()
+
+	event, err := h.ticketEvent(ctx, ticket.ID)
+	if err != nil {
+		return err
+	}
+	*ticket = *event.Ticket
+	if event.Final() {
+		return nil
+	}
+
+	err = h.watchTicket(ctx, ticket, matches, func(event entities.TicketEvent) error {
+		if event.Final() {
+			return errStopWatching
+		}
+		return nil
+	})
+	if err == nil || errors.Is(err, errStopWatching) || errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	return err
+}
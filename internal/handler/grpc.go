@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/store"
+	"matchmaker-nats/pkg/protos/gen"
+
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// matchmakerServer exposes the matchmakeHandler operations as the gRPC
+// Matchmaker service.
+type matchmakerServer struct {
+	gen.UnimplementedMatchmakerServer
+	handler *matchmakeHandler
+}
+
+// RegisterGRPC serves the handler's queues as the Matchmaker service on s.
+func (h *matchmakeHandler) RegisterGRPC(s *grpc.Server) {
+	gen.RegisterMatchmakerServer(s, &matchmakerServer{handler: h})
+}
+
+func (s *matchmakerServer) Enqueue(ctx context.Context, in *gen.MatchRequest) (*gen.EnqueueResponse, error) {
+	log.Printf("[GRPC] Received matchmaking request")
+
+	var req entities.MatchRequest
+	req.FromProto(in)
+
+	ticket, poolSize, _, reqErr := s.handler.enqueue(ctx, &req)
+	if reqErr != nil {
+		return nil, reqErr.grpcStatus()
+	}
+
+	return &gen.EnqueueResponse{
+		Ticket:   ticket.ToProto(),
+		PoolSize: poolSize,
+	}, nil
+}
+
+func (s *matchmakerServer) Cancel(ctx context.Context, in *gen.TicketRequest) (*gen.Ticket, error) {
+	log.Printf("[GRPC] Received cancel request for ticket %s", in.TicketId)
+
+	ticket, err := s.loadTicket(ctx, in.TicketId)
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := s.handler.cancel(ctx, ticket)
+	if err != nil {
+		log.Printf("[GRPC] Failed to cancel ticket %s: %v", ticket.ID, err)
+		return nil, status.Error(codes.Internal, "Failed to cancel ticket")
+	}
+	if !removed {
+		return nil, status.Errorf(codes.FailedPrecondition, "Ticket can no longer be cancelled (status: %s)", ticket.Status)
+	}
+
+	return ticket.ToProto(), nil
+}
+
+func (s *matchmakerServer) GetTicket(ctx context.Context, in *gen.TicketRequest) (*gen.TicketStatus, error) {
+	ticket, err := s.loadTicket(ctx, in.TicketId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &gen.TicketStatus{Ticket: ticket.ToProto()}
+	if ticket.Status == entities.TicketQueued {
+		position, err := s.handler.tickets.Position(ctx, ticket)
+		if err == nil {
+			response.Position = position
+		} else if !errors.Is(err, store.ErrTicketNotFound) {
+			log.Printf("[GRPC] Could not get position of ticket %s: %v", ticket.ID, err)
+		}
+	}
+	return response, nil
+}
+
+func (s *matchmakerServer) WatchTicket(in *gen.TicketRequest, stream gen.Matchmaker_WatchTicketServer) error {
+	log.Printf("[GRPC] Received watch request for ticket %s", in.TicketId)
+
+	ticket, err := s.loadTicket(stream.Context(), in.TicketId)
+	if err != nil {
+		return err
+	}
+
+	err = s.handler.streamTicket(stream.Context(), ticket, func(event entities.TicketEvent) error {
+		return stream.Send(event.ToProto())
+	})
+	if err != nil {
+		if stream.Context().Err() != nil {
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+		log.Printf("[GRPC] Watch of ticket %s failed: %v", ticket.ID, err)
+		return status.Error(codes.Internal, "Failed to watch ticket")
+	}
+	return nil
+}
+
+func (s *matchmakerServer) GetMatch(ctx context.Context, in *gen.MatchLookup) (*gen.Match, error) {
+	match, err := s.handler.matches.Get(ctx, in.MatchId)
+	if errors.Is(err, store.ErrMatchNotFound) {
+		return nil, status.Error(codes.NotFound, "Match not found")
+	}
+	if err != nil {
+		log.Printf("[GRPC] Failed to load match %s: %v", in.MatchId, err)
+		return nil, status.Error(codes.Internal, "Failed to load match")
+	}
+	return match.ToProto(), nil
+}
+
+func (s *matchmakerServer) loadTicket(ctx context.Context, ticketID string) (*entities.Ticket, error) {
+	ticket, err := s.handler.tickets.Get(ctx, ticketID)
+	if errors.Is(err, store.ErrTicketNotFound) {
+		return nil, status.Error(codes.NotFound, "Ticket not found")
+	}
+	if err != nil {
+		log.Printf("[GRPC] Failed to load ticket %s: %v", ticketID, err)
+		return nil, status.Error(codes.Internal, "Failed to load ticket")
+	}
+	return ticket, nil
+}
+
+// grpcStatus maps the HTTP status of a rejected request to a gRPC one.
+func (e *requestError) grpcStatus() error {
+	code := codes.Internal
+	switch e.status {
+	case fiber.StatusBadRequest:
+		code = codes.InvalidArgument
+	case fiber.StatusConflict:
+		code = codes.AlreadyExists
+	}
+
+	if e.ticketID != "" {
+		return status.Errorf(code, "%s (ticket %s)", e.message, e.ticketID)
+	}
+	return status.Error(code, e.message)
+}
@@ -1,98 +1,396 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"log"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"matchmaker-nats/internal/broker"
 	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/notify"
+	"matchmaker-nats/internal/queue"
+	"matchmaker-nats/internal/store"
+	"matchmaker-nats/pkg/protos/gen"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/gofiber/fiber/v2"
-	"github.com/nats-io/nats.go"
+	"github.com/google/uuid"
 )
 
 const (
-	playerPoolKey = "player_pool"
-	natsSubject   = "matchmake.request"
+	// HeaderIdempotencyKey sets the request's RequestID: retries of a
+	// POST /matchmake with the same key get the original ticket back.
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed marks responses answered from an earlier
+	// request with the same key.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxRequestIDLength = 255
 )
 
 type matchmakeHandler struct {
-	natsClient  *nats.Conn
-	redisClient *redis.Client
+	broker   broker.Broker
+	tickets  store.PoolStore
+	matches  store.MatchStore
+	notifier *notify.MatchNotifier
+	queues   map[string]queue.Config
+	// defaultQueue serves requests that don't name a queue.
+	defaultQueue string
+
+	// reconnectGrace is how long a ticket outlives its dropped WebSocket.
+	reconnectGrace time.Duration
+	// sockets counts the WebSockets watching each ticket.
+	socketsMu sync.Mutex
+	sockets   map[string]int
 }
 
-func NewMatchmakeHandler(natsClient *nats.Conn, redisClient *redis.Client) *matchmakeHandler {
+// NewMatchmakeHandler serves the given queues; the first one is the default.
+func NewMatchmakeHandler(b broker.Broker, stores store.Stores, notifier *notify.MatchNotifier, queues []queue.Config) *matchmakeHandler {
+	byName := make(map[string]queue.Config, len(queues))
+	for _, q := range queues {
+		byName[q.Name] = q
+	}
+
 	return &matchmakeHandler{
-		natsClient:  natsClient,
-		redisClient: redisClient,
+		broker:         b,
+		tickets:        stores.Pool,
+		matches:        stores.Matches,
+		notifier:       notifier,
+		queues:         byName,
+		defaultQueue:   queues[0].Name,
+		reconnectGrace: ReconnectGrace,
+		sockets:        make(map[string]int),
 	}
 }
 
+// requestError is a rejected matchmaking request; status and message are
+// returned to the client as is.
+type requestError struct {
+	status   int
+	message  string
+	ticketID string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+func (e *requestError) respond(c *fiber.Ctx) error {
+	body := fiber.Map{
+		"error": e.message,
+	}
+	if e.ticketID != "" {
+		body["ticket_id"] = e.ticketID
+	}
+	return c.Status(e.status).JSON(body)
+}
+
 func (h *matchmakeHandler) Executer(c *fiber.Ctx) error {
 	ctx := c.Context()
 
 	log.Printf("[HANDLER] Received matchmaking request from IP: %s", c.IP())
 
 	var req entities.MatchRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := parseMatchRequest(c, &req); err != nil {
 		log.Printf("[HANDLER] Failed to parse request body: %v", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid request body",
 		})
 	}
 
-	log.Printf("[HANDLER] Request parsed successfully - Player ID: %s, Ping: %dms", req.Player.ID, req.Player.Ping)
+	if key := c.Get(HeaderIdempotencyKey); key != "" {
+		req.RequestID = key
+	}
+
+	ticket, poolSize, replayed, reqErr := h.enqueue(ctx, &req)
+	if reqErr != nil {
+		return reqErr.respond(c)
+	}
+	if replayed {
+		c.Set(HeaderIdempotentReplayed, "true")
+	}
+
+	log.Printf("[HANDLER] Matchmaking request completed for player %s", req.Player.ID)
+
+	return respond(c, fiber.StatusOK, fiber.Map{
+		"message":   "Matchmaking request sent successfully",
+		"ticket_id": ticket.ID,
+		"player":    ticket.Player,
+		"party":     ticket.Members,
+		"queue":     ticket.Queue,
+		"pool_size": poolSize,
+	}, &gen.EnqueueResponse{
+		Ticket:   ticket.ToProto(),
+		PoolSize: poolSize,
+	})
+}
+
+// enqueue validates the request, queues a ticket for it and triggers the
+// queue's workers. It returns the ticket and the pool size after enqueueing.
+// A retry carrying the RequestID of an earlier request returns that request's
+// ticket untouched, with replayed set, and triggers nothing.
+func (h *matchmakeHandler) enqueue(ctx context.Context, req *entities.MatchRequest) (ticket *entities.Ticket, poolSize int64, replayed bool, reqErr *requestError) {
+	if req.Queue == "" {
+		req.Queue = h.defaultQueue
+	}
+
+	log.Printf("[HANDLER] Request parsed successfully - Player ID: %s, Ping: %dms, Party size: %d, Queue: %s", req.Player.ID, req.Player.Ping, 1+len(req.Party), req.Queue)
+
+	q, ok := h.queues[req.Queue]
+	if !ok {
+		log.Printf("[HANDLER] Rejecting request from player %s: unknown queue %s", req.Player.ID, req.Queue)
+		return nil, 0, false, &requestError{status: fiber.StatusBadRequest, message: "Unknown queue"}
+	}
+
+	if msg := validateRequest(req, q); msg != "" {
+		log.Printf("[HANDLER] Rejecting request from player %s: %s", req.Player.ID, msg)
+		return nil, 0, false, &requestError{status: fiber.StatusBadRequest, message: msg}
+	}
+
+	// Add a ticket for the player to the FIFO pool in Redis (Sorted Set by timestamp)
+	now := time.Now()
+	ticket = &entities.Ticket{
+		ID:        uuid.NewString(),
+		Player:    req.Player,
+		Members:   req.Party,
+		Queue:     q.Name,
+		Status:    entities.TicketQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
 
-	// Add player to FIFO pool in Redis (Sorted Set by timestamp)
-	log.Printf("[HANDLER] Adding player %s to Redis pool with timestamp %d", req.Player.ID, time.Now().Unix())
+	log.Printf("[HANDLER] Adding ticket %s for player %s to Redis pool %s with timestamp %d", ticket.ID, req.Player.ID, q.Name, now.Unix())
 
-	_, err := h.redisClient.ZAdd(ctx, playerPoolKey, &redis.Z{
-		Score:  float64(time.Now().Unix()),
-		Member: req.Player.ID,
-	}).Result()
+	ticketID, result, err := h.tickets.Enqueue(ctx, ticket, req.RequestID)
 	if err != nil {
 		log.Printf("[HANDLER] Failed to add player %s to Redis pool: %v", req.Player.ID, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to add player to pool",
-		})
+		return nil, 0, false, &requestError{status: fiber.StatusInternalServerError, message: "Failed to add player to pool"}
+	}
+	switch result {
+	case store.AlreadyQueued:
+		log.Printf("[HANDLER] Player %s or a party member is already queued with ticket %s", req.Player.ID, ticketID)
+		return nil, 0, false, &requestError{status: fiber.StatusConflict, message: "Player is already queued", ticketID: ticketID}
+	case store.Replayed:
+		log.Printf("[HANDLER] Request %s of player %s was already handled, returning ticket %s", req.RequestID, req.Player.ID, ticketID)
+		return h.replay(ctx, ticketID)
 	}
 
-	log.Printf("[HANDLER] Player %s added to Redis pool successfully", req.Player.ID)
+	log.Printf("[HANDLER] Player %s added to pool successfully", req.Player.ID)
 
 	// Get current pool size
-	poolSize, err := h.redisClient.ZCard(ctx, playerPoolKey).Result()
+	poolSize, err = h.tickets.Size(ctx, q.Name)
 	if err != nil {
 		log.Printf("[HANDLER] Could not get pool size: %v", err)
 	} else {
 		log.Printf("[HANDLER] Current pool size: %d players", poolSize)
 	}
 
-	// Publish request to NATS for worker processing
-	log.Printf("[HANDLER] Publishing matchmaking request to NATS subject: %s", natsSubject)
+	// Publish request to the broker for worker processing
+	log.Printf("[HANDLER] Publishing matchmaking request for queue %s", q.Name)
+
+	// The ticket is queued either way: without the trigger it waits for the
+	// queue's next tick, so a failed publish isn't worth failing the request.
+	req.TicketID = ticket.ID
+	if err := h.broker.PublishRequest(ctx, q.Name, req); err != nil {
+		log.Printf("[HANDLER] Failed to publish matchmaking request for ticket %s, leaving it to the next tick: %v", ticket.ID, err)
+	} else {
+		log.Printf("[HANDLER] Request published successfully")
+	}
+
+	return ticket, poolSize, false, nil
+}
+
+// replay loads the ticket an earlier request with the same RequestID created.
+func (h *matchmakeHandler) replay(ctx context.Context, ticketID string) (*entities.Ticket, int64, bool, *requestError) {
+	ticket, err := h.tickets.Get(ctx, ticketID)
+	if errors.Is(err, store.ErrTicketNotFound) {
+		// The key outlived its ticket; the request can't be answered as before.
+		return nil, 0, false, &requestError{status: fiber.StatusConflict, message: "Request ID was already used"}
+	}
+	if err != nil {
+		log.Printf("[HANDLER] Failed to load ticket %s: %v", ticketID, err)
+		return nil, 0, false, &requestError{status: fiber.StatusInternalServerError, message: "Failed to load ticket"}
+	}
+
+	poolSize, err := h.tickets.Size(ctx, ticket.Queue)
+	if err != nil {
+		log.Printf("[HANDLER] Could not get pool size: %v", err)
+	}
+	return ticket, poolSize, true, nil
+}
+
+// validateRequest returns why the request can't be queued, or "" if it can.
+func validateRequest(req *entities.MatchRequest, q queue.Config) string {
+	if req.Player.ID == "" {
+		return "Player ID is required"
+	}
+	if !validPlayerID(req.Player.ID) {
+		return "Invalid player ID"
+	}
+	if len(req.RequestID) > maxRequestIDLength {
+		return "Request ID is too long"
+	}
+	if 1+len(req.Party) > q.MaxPartySize() {
+		return "Party is too large"
+	}
+
+	seen := map[string]bool{req.Player.ID: true}
+	for _, member := range req.Party {
+		if member.ID == "" {
+			return "Party member ID is required"
+		}
+		if !validPlayerID(member.ID) {
+			return "Invalid party member ID"
+		}
+		if seen[member.ID] {
+			return "Duplicate player in party"
+		}
+		seen[member.ID] = true
+	}
+	return ""
+}
+
+// validPlayerID reports whether the ID is safe to use as a NATS subject
+// token: matches are published on PlayerMatchedSubject.
+func validPlayerID(id string) bool {
+	if !utf8.ValidString(id) || strings.ContainsAny(id, ".*>") {
+		return false
+	}
+	for _, r := range id {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *matchmakeHandler) GetTicket(c *fiber.Ctx) error {
+	ctx := c.Context()
+	ticketID := c.Params("ticket")
+
+	log.Printf("[HANDLER] Received ticket status request for %s", ticketID)
+
+	ticket, err := h.tickets.Get(ctx, ticketID)
+	if errors.Is(err, store.ErrTicketNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ticket not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[HANDLER] Failed to load ticket %s: %v", ticketID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load ticket",
+		})
+	}
+
+	// ?wait= turns the lookup into a long-poll that returns as soon as the
+	// ticket leaves the queue.
+	if wait := c.Query("wait"); wait != "" && ticket.Status == entities.TicketQueued {
+		timeout, err := time.ParseDuration(wait)
+		if err != nil || timeout <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid wait duration",
+			})
+		}
+		if timeout > MaxTicketWait {
+			timeout = MaxTicketWait
+		}
+
+		log.Printf("[HANDLER] Waiting up to %s for ticket %s to leave the queue", timeout, ticketID)
+
+		if err := h.waitForTicket(ctx, ticket, timeout); err != nil {
+			log.Printf("[HANDLER] Failed to wait for ticket %s: %v", ticketID, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to load ticket",
+			})
+		}
+	}
+
+	response := fiber.Map{
+		"ticket": ticket,
+	}
+	status := &gen.TicketStatus{Ticket: ticket.ToProto()}
+
+	if ticket.Status == entities.TicketQueued {
+		position, err := h.tickets.Position(ctx, ticket)
+		if err == nil {
+			response["position"] = position
+			status.Position = position
+		} else if !errors.Is(err, store.ErrTicketNotFound) {
+			log.Printf("[HANDLER] Could not get position of ticket %s: %v", ticketID, err)
+		}
+	}
 
-	reqData, err := req.ToJSON()
+	return respond(c, fiber.StatusOK, response, status)
+}
+
+func (h *matchmakeHandler) CancelTicket(c *fiber.Ctx) error {
+	ctx := c.Context()
+	ticketID := c.Params("ticket")
+
+	log.Printf("[HANDLER] Received cancel request for ticket %s", ticketID)
+
+	ticket, err := h.tickets.Get(ctx, ticketID)
+	if errors.Is(err, store.ErrTicketNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Ticket not found",
+		})
+	}
 	if err != nil {
-		log.Printf("[HANDLER] Failed to serialize request for NATS: %v", err)
+		log.Printf("[HANDLER] Failed to load ticket %s: %v", ticketID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to serialize request",
+			"error": "Failed to load ticket",
 		})
 	}
 
-	err = h.natsClient.Publish(natsSubject, reqData)
+	removed, err := h.cancel(ctx, ticket)
 	if err != nil {
-		log.Printf("[HANDLER] Failed to publish to NATS: %v", err)
+		log.Printf("[HANDLER] Failed to cancel ticket %s: %v", ticketID, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to publish matchmaking request",
+			"error": "Failed to cancel ticket",
 		})
 	}
 
-	log.Printf("[HANDLER] Request published to NATS successfully")
+	// The ticket already reached a final state.
+	if !removed {
+		log.Printf("[HANDLER] Ticket %s is no longer queued (status: %s)", ticketID, ticket.Status)
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":  "Ticket can no longer be cancelled",
+			"ticket": ticket,
+		})
+	}
 
-	log.Printf("[HANDLER] Matchmaking request completed for player %s", req.Player.ID)
+	log.Printf("[HANDLER] Ticket %s cancelled for player %s", ticketID, ticket.Player.ID)
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message":   "Matchmaking request sent successfully",
-		"player":    req.Player,
-		"pool_size": poolSize,
-	})
+	return respond(c, fiber.StatusOK, fiber.Map{
+		"ticket": ticket,
+	}, ticket.ToProto())
+}
+
+// cancel marks a ticket cancelled and takes it out of its pool. It reports
+// false, without touching the ticket, if the ticket was no longer queued.
+// Tickets claimed by a pass are cancelled too: the status flips first, so
+// the pass drops the ticket instead of matching or requeueing it.
+func (h *matchmakeHandler) cancel(ctx context.Context, ticket *entities.Ticket) (bool, error) {
+	err := h.tickets.SetStatus(ctx, ticket, entities.TicketCancelled, "")
+	if errors.Is(err, store.ErrTicketNotQueued) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// The ticket is cancelled either way; whatever is left in the pool is
+	// dropped by the next pass.
+	if _, err := h.tickets.Dequeue(ctx, ticket); err != nil {
+		log.Printf("[HANDLER] Failed to remove cancelled ticket %s from pool: %v", ticket.ID, err)
+	}
+	if err := h.tickets.Release(ctx, ticket.Queue, []string{ticket.ID}); err != nil {
+		log.Printf("[HANDLER] Failed to release claim of cancelled ticket %s: %v", ticket.ID, err)
+	}
+	return true, nil
 }
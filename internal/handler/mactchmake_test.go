@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"matchmaker-nats/internal/broker"
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+	"matchmaker-nats/internal/store"
+)
+
+func TestValidateRequest(t *testing.T) {
+	q := queue.Default("test")
+
+	tests := []struct {
+		name   string
+		player string
+		party  []string
+		want   string
+	}{
+		{name: "solo", player: "player-1"},
+		{name: "party", player: "player-1", party: []string{"player-2", "player-3"}},
+		{name: "unicode", player: "jogador_ñ-1"},
+		{name: "missing", player: "", want: "Player ID is required"},
+		{name: "dot", player: "a.b", want: "Invalid player ID"},
+		{name: "wildcard", player: "*", want: "Invalid player ID"},
+		{name: "full wildcard", player: ">", want: "Invalid player ID"},
+		{name: "space", player: "a b", want: "Invalid player ID"},
+		{name: "protocol injection", player: "a\r\nPUB x 1", want: "Invalid player ID"},
+		{name: "control", player: "a\x00b", want: "Invalid player ID"},
+		{name: "invalid utf8", player: "a\xffb", want: "Invalid player ID"},
+		{name: "member dot", player: "player-1", party: []string{"b.c"}, want: "Invalid party member ID"},
+		{name: "member tab", player: "player-1", party: []string{"b\tc"}, want: "Invalid party member ID"},
+		{name: "member missing", player: "player-1", party: []string{""}, want: "Party member ID is required"},
+		{name: "duplicate", player: "player-1", party: []string{"player-1"}, want: "Duplicate player in party"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &entities.MatchRequest{Player: entities.Player{ID: tt.player}}
+			for _, id := range tt.party {
+				req.Party = append(req.Party, entities.Player{ID: id})
+			}
+
+			if got := validateRequest(req, q); got != tt.want {
+				t.Errorf("validateRequest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// unreachableBroker fails every trigger.
+type unreachableBroker struct {
+	broker.Broker
+}
+
+func (unreachableBroker) PublishRequest(ctx context.Context, queueName string, req *entities.MatchRequest) error {
+	return errors.New("broker unreachable")
+}
+
+func TestEnqueueWithoutTrigger(t *testing.T) {
+	ctx := context.Background()
+	stores := store.NewMemoryStores()
+	h := NewMatchmakeHandler(unreachableBroker{broker.NewMemoryBroker()}, stores, nil, []queue.Config{queue.Default("test")})
+
+	ticket, poolSize, _, reqErr := h.enqueue(ctx, &entities.MatchRequest{Player: entities.Player{ID: "player-1"}})
+	if reqErr != nil {
+		t.Fatalf("enqueue failed: %v", reqErr)
+	}
+	if poolSize != 1 {
+		t.Errorf("pool size = %d, want 1", poolSize)
+	}
+
+	// The queue's tick matches the ticket without the trigger.
+	stored, err := stores.Pool.Get(ctx, ticket.ID)
+	if err != nil || stored.Status != entities.TicketQueued {
+		t.Errorf("ticket = %+v, %v; want queued", stored, err)
+	}
+}
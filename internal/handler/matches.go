@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"matchmaker-nats/internal/store"
+	"matchmaker-nats/pkg/protos/gen"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+type matchesHandler struct {
+	matches    store.MatchStore
+	deliveries store.DeliveryStore
+}
+
+func NewMatchesHandler(stores store.Stores) *matchesHandler {
+	return &matchesHandler{
+		matches:    stores.Matches,
+		deliveries: stores.Deliveries,
+	}
+}
+
+func (h *matchesHandler) GetMatch(c *fiber.Ctx) error {
+	matchID := c.Params("id")
+
+	log.Printf("[HANDLER] Received match lookup for %s", matchID)
+
+	match, err := h.matches.Get(c.Context(), matchID)
+	if errors.Is(err, store.ErrMatchNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Match not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[HANDLER] Failed to load match %s: %v", matchID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load match",
+		})
+	}
+
+	return respond(c, fiber.StatusOK, match, match.ToProto())
+}
+
+// GetDelivery reports how pushing the match to its queue's webhook went.
+func (h *matchesHandler) GetDelivery(c *fiber.Ctx) error {
+	matchID := c.Params("id")
+
+	log.Printf("[HANDLER] Received webhook delivery lookup for match %s", matchID)
+
+	delivery, err := h.deliveries.Get(c.Context(), matchID)
+	if errors.Is(err, store.ErrDeliveryNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Delivery not found",
+		})
+	}
+	if err != nil {
+		log.Printf("[HANDLER] Failed to load delivery of match %s: %v", matchID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load delivery",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(delivery)
+}
+
+func (h *matchesHandler) ListPlayerMatches(c *fiber.Ctx) error {
+	playerID := c.Params("id")
+	cursor := c.Query("cursor")
+
+	limit := c.QueryInt("limit", defaultHistoryLimit)
+	if limit < 1 || limit > maxHistoryLimit {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "limit must be between 1 and 100",
+		})
+	}
+
+	log.Printf("[HANDLER] Received match history request for player %s (limit: %d, cursor: %q)", playerID, limit, cursor)
+
+	matches, next, err := h.matches.ListByPlayer(c.Context(), playerID, limit, cursor)
+	if err != nil {
+		log.Printf("[HANDLER] Failed to load match history of player %s: %v", playerID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load match history",
+		})
+	}
+
+	list := &gen.MatchList{NextCursor: next}
+	for i := range matches {
+		list.Matches = append(list.Matches, matches[i].ToProto())
+	}
+
+	return respond(c, fiber.StatusOK, fiber.Map{
+		"matches":     matches,
+		"next_cursor": next,
+	}, list)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"strings"
+
+	"matchmaker-nats/internal/broker"
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/pkg/protos/gen"
+
+	"github.com/gofiber/fiber/v2"
+	"google.golang.org/protobuf/proto"
+)
+
+// Native clients may speak protobuf instead of JSON: request bodies are
+// decoded according to Content-Type and responses encoded according to
+// Accept, JSON being the default for both. Errors are always JSON.
+
+func isProtobuf(contentType string) bool {
+	return strings.HasPrefix(contentType, broker.ContentTypeProtobuf)
+}
+
+// wantsProtobuf reports whether the client prefers protobuf responses.
+func wantsProtobuf(c *fiber.Ctx) bool {
+	return c.Accepts(fiber.MIMEApplicationJSON, broker.ContentTypeProtobuf) == broker.ContentTypeProtobuf
+}
+
+// parseMatchRequest decodes a gen.MatchRequest or a JSON body.
+func parseMatchRequest(c *fiber.Ctx, req *entities.MatchRequest) error {
+	if !isProtobuf(c.Get(fiber.HeaderContentType)) {
+		return c.BodyParser(req)
+	}
+
+	var reqProto gen.MatchRequest
+	if err := proto.Unmarshal(c.Body(), &reqProto); err != nil {
+		return err
+	}
+	req.FromProto(&reqProto)
+	return nil
+}
+
+// respond sends protoBody to clients that asked for protobuf and jsonBody to
+// everyone else.
+func respond(c *fiber.Ctx, status int, jsonBody interface{}, protoBody proto.Message) error {
+	if !wantsProtobuf(c) {
+		return c.Status(status).JSON(jsonBody)
+	}
+
+	data, err := proto.Marshal(protoBody)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to encode response",
+		})
+	}
+	c.Set(fiber.HeaderContentType, broker.ContentTypeProtobuf)
+	return c.Status(status).Send(data)
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/store"
+)
+
+// watchInterval is how often a watched ticket is reloaded to report its
+// position and to catch outcomes no match event was received for.
+const watchInterval = 2 * time.Second
+
+// watchTicket pushes the ticket's events to send until it leaves the queue,
+// send fails or ctx is done. matches carries the match events of the ticket's
+// leader; it is subscribed before the ticket is queued so none is missed.
+func (h *matchmakeHandler) watchTicket(ctx context.Context, ticket *entities.Ticket, matches <-chan entities.Match, send func(entities.TicketEvent) error) error {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	var lastPosition int64
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case match := <-matches:
+			ticket.Status = entities.TicketMatched
+			ticket.MatchID = match.MatchID
+			return send(entities.TicketEvent{Type: entities.EventMatched, Ticket: ticket, Match: &match})
+
+		case <-ticker.C:
+			event, err := h.ticketEvent(ctx, ticket.ID)
+			if err != nil {
+				log.Printf("[HANDLER] Failed to refresh ticket %s: %v", ticket.ID, err)
+				continue
+			}
+			// Position 0 means a pass claimed the ticket; it comes back with
+			// its place or leaves the queue, so there is nothing to report.
+			if event.Type == entities.EventPosition && (event.Position == lastPosition || event.Position == 0) {
+				continue
+			}
+			lastPosition = event.Position
+			*ticket = *event.Ticket
+
+			if err := send(event); err != nil {
+				return err
+			}
+			if event.Final() {
+				return nil
+			}
+		}
+	}
+}
+
+// ticketEvent describes the ticket's current state: its position while it is
+// queued, otherwise its outcome, with the match when there is one.
+func (h *matchmakeHandler) ticketEvent(ctx context.Context, ticketID string) (entities.TicketEvent, error) {
+	ticket, err := h.tickets.Get(ctx, ticketID)
+	if err != nil {
+		return entities.TicketEvent{}, err
+	}
+
+	switch ticket.Status {
+	case entities.TicketQueued:
+		position, err := h.tickets.Position(ctx, ticket)
+		if err != nil && !errors.Is(err, store.ErrTicketNotFound) {
+			return entities.TicketEvent{}, err
+		}
+		// A queued ticket missing from the pool is being matched right now.
+		return entities.TicketEvent{Type: entities.EventPosition, Ticket: ticket, Position: position}, nil
+
+	case entities.TicketMatched:
+		match, err := h.matches.Get(ctx, ticket.MatchID)
+		if err != nil {
+			return entities.TicketEvent{}, err
+		}
+		return entities.TicketEvent{Type: entities.EventMatched, Ticket: ticket, Match: match}, nil
+
+	case entities.TicketCancelled:
+		return entities.TicketEvent{Type: entities.EventCancelled, Ticket: ticket}, nil
+
+	default:
+		return entities.TicketEvent{Type: entities.EventExpired, Ticket: ticket}, nil
+	}
+}
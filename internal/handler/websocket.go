@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+
+	"github.com/gofiber/contrib/websocket"
+)
+
+// ReconnectGrace is how long a ticket stays queued after its WebSocket drops.
+// Reconnecting to the same instance with the same request ID within it keeps
+// the ticket.
+const ReconnectGrace = 10 * time.Second
+
+// WebSocket enqueues the MatchRequest sent as the connection's first message
+// and streams the ticket's events back until it leaves the queue. Closing the
+// connection while still queued cancels the ticket unless the client comes
+// back within ReconnectGrace.
+func (h *matchmakeHandler) WebSocket(conn *websocket.Conn) {
+	log.Printf("[HANDLER] WebSocket connection opened from IP: %s", conn.RemoteAddr())
+
+	var req entities.MatchRequest
+	if err := conn.ReadJSON(&req); err != nil {
+		log.Printf("[HANDLER] Failed to read matchmaking request from WebSocket: %v", err)
+		conn.WriteJSON(entities.TicketEvent{Type: entities.EventError, Error: "Invalid request body"})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Listen before enqueueing, a match can be formed right after.
+	matches, unsubscribe := h.notifier.Subscribe(req.Player.ID)
+	defer unsubscribe()
+
+	ticket, _, replayed, reqErr := h.enqueue(ctx, &req)
+	if reqErr != nil {
+		conn.WriteJSON(entities.TicketEvent{Type: entities.EventError, Error: reqErr.message})
+		return
+	}
+
+	log.Printf("[HANDLER] Ticket %s for player %s is watched over WebSocket", ticket.ID, req.Player.ID)
+
+	h.socketOpened(ticket.ID)
+
+	// The client has nothing more to say; a failing read means it went away.
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(event entities.TicketEvent) error {
+		return conn.WriteJSON(event)
+	}
+
+	var err error
+	if replayed {
+		// A reconnect with the same request ID picks the ticket up where it
+		// is, as long as it came back within ReconnectGrace.
+		err = h.streamTicket(ctx, ticket, send)
+	} else {
+		position, _ := h.tickets.Position(ctx, ticket)
+		err = send(entities.TicketEvent{Type: entities.EventQueued, Ticket: ticket, Position: position})
+		if err == nil {
+			err = h.watchTicket(ctx, ticket, matches, send)
+		}
+	}
+	if err == nil {
+		h.socketClosed(ticket.ID)
+		log.Printf("[HANDLER] Ticket %s left the queue as %s, closing WebSocket", ticket.ID, ticket.Status)
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		return
+	}
+
+	log.Printf("[HANDLER] WebSocket for ticket %s closed: %v", ticket.ID, err)
+	if h.socketClosed(ticket.ID) {
+		go h.cancelAfterGrace(ticket)
+	}
+}
+
+// socketOpened records a WebSocket watching the ticket.
+func (h *matchmakeHandler) socketOpened(ticketID string) {
+	h.socketsMu.Lock()
+	defer h.socketsMu.Unlock()
+
+	h.sockets[ticketID]++
+}
+
+// socketClosed forgets a WebSocket of the ticket and reports whether it was
+// the last one.
+func (h *matchmakeHandler) socketClosed(ticketID string) bool {
+	h.socketsMu.Lock()
+	defer h.socketsMu.Unlock()
+
+	h.sockets[ticketID]--
+	if h.sockets[ticketID] > 0 {
+		return false
+	}
+	delete(h.sockets, ticketID)
+	return true
+}
+
+// cancelAfterGrace cancels the ticket of a dropped WebSocket unless the
+// client reconnected to this instance within reconnectGrace.
+func (h *matchmakeHandler) cancelAfterGrace(ticket *entities.Ticket) {
+	time.Sleep(h.reconnectGrace)
+
+	h.socketsMu.Lock()
+	reconnected := h.sockets[ticket.ID] > 0
+	h.socketsMu.Unlock()
+	if reconnected {
+		log.Printf("[HANDLER] Ticket %s kept, client reconnected", ticket.ID)
+		return
+	}
+
+	removed, err := h.cancel(context.Background(), ticket)
+	if err != nil {
+		log.Printf("[HANDLER] Failed to cancel ticket %s after disconnect: %v", ticket.ID, err)
+	} else if removed {
+		log.Printf("[HANDLER] Ticket %s cancelled after client disconnected", ticket.ID)
+	}
+}
@@ -0,0 +1,176 @@
+package lease
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"matchmaker-nats/internal/store"
+)
+
+// DefaultTTL is how long a queue stays with a replica that stopped renewing
+// its lease, i.e. the worst-case failover time.
+const DefaultTTL = 5 * time.Second
+
+// Manager decides which queues this replica runs the matching loop of. The
+// live replicas split the queues between them by rendezvous hashing, and a
+// lease per queue makes sure only one of them owns it at a time, even while
+// their views of who is alive disagree.
+type Manager struct {
+	leases  store.LeaseStore
+	replica string
+	ttl     time.Duration
+	queues  []string
+
+	mu sync.Mutex
+	// validUntil is when each held lease runs out unless renewed.
+	validUntil map[string]time.Time
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func NewManager(leases store.LeaseStore, replica string, ttl time.Duration, queues []string) *Manager {
+	return &Manager{
+		leases:     leases,
+		replica:    replica,
+		ttl:        ttl,
+		queues:     queues,
+		validUntil: make(map[string]time.Time),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+}
+
+// Start joins the replicas and keeps the owned leases renewed, three times
+// per TTL so a slow round doesn't lose them.
+func (m *Manager) Start() {
+	log.Printf("[LEASE] Replica %s sharing %d queues with lease TTL %s", m.replica, len(m.queues), m.ttl)
+	m.renew()
+
+	go func() {
+		defer close(m.stopped)
+
+		ticker := time.NewTicker(m.ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.done:
+				return
+			case <-ticker.C:
+				m.renew()
+			}
+		}
+	}()
+}
+
+// Stop hands every owned queue back and leaves the replicas, so the others
+// take over without waiting for the leases to run out.
+func (m *Manager) Stop() {
+	close(m.done)
+	<-m.stopped
+
+	ctx := context.Background()
+	m.mu.Lock()
+	for queueName := range m.validUntil {
+		if err := m.leases.Release(ctx, queueName, m.replica); err != nil {
+			log.Printf("[LEASE] Failed to release queue %s: %v", queueName, err)
+		}
+	}
+	m.validUntil = make(map[string]time.Time)
+	m.mu.Unlock()
+
+	if err := m.leases.Leave(ctx, m.replica); err != nil {
+		log.Printf("[LEASE] Failed to leave replicas: %v", err)
+	}
+}
+
+// Owns reports whether this replica holds the queue's lease right now.
+func (m *Manager) Owns(queueName string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return time.Now().Before(m.validUntil[queueName])
+}
+
+// renew takes or renews the lease of every queue assigned to this replica
+// and releases the ones assigned elsewhere.
+func (m *Manager) renew() {
+	ctx := context.Background()
+
+	if err := m.leases.Heartbeat(ctx, m.replica, m.ttl); err != nil {
+		log.Printf("[LEASE] Heartbeat of replica %s failed: %v", m.replica, err)
+	}
+	replicas, err := m.leases.Replicas(ctx)
+	if err != nil {
+		log.Printf("[LEASE] Could not list replicas: %v", err)
+		replicas = []string{m.replica}
+	}
+
+	for _, queueName := range m.queues {
+		if assignee(queueName, replicas) != m.replica {
+			m.release(ctx, queueName)
+			continue
+		}
+
+		// Measured before the call so the local view never outlives the
+		// lease in the store.
+		start := time.Now()
+		held, err := m.leases.Acquire(ctx, queueName, m.replica, m.ttl)
+		if err != nil {
+			log.Printf("[LEASE] Could not renew lease of queue %s: %v", queueName, err)
+			continue
+		}
+
+		m.mu.Lock()
+		_, owned := m.validUntil[queueName]
+		if held {
+			m.validUntil[queueName] = start.Add(m.ttl)
+		} else {
+			delete(m.validUntil, queueName)
+		}
+		m.mu.Unlock()
+
+		if held && !owned {
+			log.Printf("[LEASE] Replica %s now owns queue %s", m.replica, queueName)
+		} else if !held && owned {
+			log.Printf("[LEASE] Replica %s lost queue %s to another replica", m.replica, queueName)
+		}
+	}
+}
+
+func (m *Manager) release(ctx context.Context, queueName string) {
+	m.mu.Lock()
+	_, owned := m.validUntil[queueName]
+	delete(m.validUntil, queueName)
+	m.mu.Unlock()
+
+	if !owned {
+		return
+	}
+	if err := m.leases.Release(ctx, queueName, m.replica); err != nil {
+		log.Printf("[LEASE] Failed to release queue %s: %v", queueName, err)
+		return
+	}
+	log.Printf("[LEASE] Replica %s handed queue %s over", m.replica, queueName)
+}
+
+// assignee picks the replica with the highest hash of queue and replica, so
+// a replica joining or leaving only moves the queues it wins or held.
+func assignee(queueName string, replicas []string) string {
+	var best string
+	var bestScore uint64
+	for _, replica := range replicas {
+		h := fnv.New64a()
+		h.Write([]byte(queueName))
+		h.Write([]byte{0})
+		h.Write([]byte(replica))
+		if score := h.Sum64(); best == "" || score > bestScore {
+			best, bestScore = replica, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,168 @@
+package lease
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"matchmaker-nats/internal/store"
+)
+
+func testQueues(n int) []string {
+	queues := make([]string, n)
+	for i := range queues {
+		queues[i] = fmt.Sprintf("queue-%02d", i)
+	}
+	return queues
+}
+
+func TestAssignee(t *testing.T) {
+	queues := testQueues(60)
+	replicas := []string{"replica-a", "replica-b", "replica-c"}
+
+	if got := assignee("queue-00", nil); got != "" {
+		t.Errorf("assignee without replicas = %q, want none", got)
+	}
+
+	shares := make(map[string]int)
+	for _, queueName := range queues {
+		owner := assignee(queueName, replicas)
+		shares[owner]++
+
+		reversed := slices.Clone(replicas)
+		slices.Reverse(reversed)
+		if got := assignee(queueName, reversed); got != owner {
+			t.Errorf("%s goes to %s or %s depending on replica order", queueName, owner, got)
+		}
+
+		// Only the queues of a leaving replica move, and only the queues a
+		// joining replica wins.
+		if left := assignee(queueName, replicas[:2]); owner != "replica-c" && left != owner {
+			t.Errorf("%s moved from %s to %s when replica-c left", queueName, owner, left)
+		}
+		if joined := assignee(queueName, append(slices.Clone(replicas), "replica-d")); joined != "replica-d" && joined != owner {
+			t.Errorf("%s moved from %s to %s when replica-d joined", queueName, owner, joined)
+		}
+	}
+
+	for _, replica := range replicas {
+		if shares[replica] < len(queues)/6 {
+			t.Errorf("%s got %d of %d queues: %v", replica, shares[replica], len(queues), shares)
+		}
+	}
+}
+
+// cluster runs the renewal rounds of several managers by hand.
+type cluster struct {
+	t        *testing.T
+	queues   []string
+	managers map[string]*Manager
+}
+
+func newCluster(t *testing.T, leases store.LeaseStore, ttl time.Duration, queues []string, replicas ...string) *cluster {
+	c := &cluster{t: t, queues: queues, managers: make(map[string]*Manager)}
+	for _, replica := range replicas {
+		c.managers[replica] = NewManager(leases, replica, ttl, queues)
+	}
+	return c
+}
+
+// renew runs one round of the given replicas, checking after every step
+// that no queue has two owners.
+func (c *cluster) renew(replicas ...string) {
+	c.t.Helper()
+	for _, replica := range replicas {
+		c.managers[replica].renew()
+		for _, queueName := range c.queues {
+			if owners := c.owners(queueName); len(owners) > 1 {
+				c.t.Fatalf("%s owned by %v after %s renewed", queueName, owners, replica)
+			}
+		}
+	}
+}
+
+func (c *cluster) owners(queueName string) []string {
+	var owners []string
+	for replica, m := range c.managers {
+		if m.Owns(queueName) {
+			owners = append(owners, replica)
+		}
+	}
+	return owners
+}
+
+// assertOwnedBy checks every queue has exactly one owner, the one the live
+// replicas assign it to.
+func (c *cluster) assertOwnedBy(live ...string) {
+	c.t.Helper()
+	for _, queueName := range c.queues {
+		want := assignee(queueName, live)
+		if owners := c.owners(queueName); len(owners) != 1 || owners[0] != want {
+			c.t.Errorf("%s owned by %v, want [%s]", queueName, owners, want)
+		}
+	}
+}
+
+func TestManagerExactlyOneOwner(t *testing.T) {
+	replicas := []string{"replica-a", "replica-b", "replica-c"}
+	c := newCluster(t, store.NewMemoryLeaseStore(), time.Minute, testQueues(12), replicas...)
+
+	// The first rounds start from different views of who is alive.
+	for round := 0; round < 3; round++ {
+		c.renew(replicas...)
+	}
+	c.assertOwnedBy(replicas...)
+}
+
+func TestManagerFailover(t *testing.T) {
+	const ttl = 100 * time.Millisecond
+	c := newCluster(t, store.NewMemoryLeaseStore(), ttl, testQueues(12), "replica-a", "replica-b")
+	c.renew("replica-a", "replica-b", "replica-a", "replica-b")
+	c.assertOwnedBy("replica-a", "replica-b")
+
+	// replica-b stops renewing without handing its queues over.
+	c.renew("replica-a")
+	c.assertOwnedBy("replica-a", "replica-b")
+
+	time.Sleep(ttl + 20*time.Millisecond)
+	for _, queueName := range c.queues {
+		if c.managers["replica-b"].Owns(queueName) {
+			t.Errorf("replica-b still owns %s after its lease ran out", queueName)
+		}
+	}
+
+	c.renew("replica-a")
+	c.assertOwnedBy("replica-a")
+}
+
+func TestManagerHandover(t *testing.T) {
+	leases := store.NewMemoryLeaseStore()
+	queues := testQueues(12)
+	c := newCluster(t, leases, time.Minute, queues, "replica-a", "replica-b")
+
+	c.renew("replica-a")
+	c.assertOwnedBy("replica-a")
+
+	// replica-b joins; its queues move once replica-a lets go of them.
+	c.renew("replica-b", "replica-a", "replica-b")
+	c.assertOwnedBy("replica-a", "replica-b")
+
+	moved := 0
+	for _, queueName := range queues {
+		if assignee(queueName, []string{"replica-a", "replica-b"}) == "replica-b" {
+			moved++
+		}
+	}
+	if moved == 0 {
+		t.Fatal("replica-b took over no queues")
+	}
+
+	// Stopping hands the queues back without waiting for the leases to run
+	// out.
+	c.managers["replica-a"].Start()
+	c.managers["replica-a"].Stop()
+	delete(c.managers, "replica-a")
+	c.renew("replica-b")
+	c.assertOwnedBy("replica-b")
+}
@@ -0,0 +1,79 @@
+package matcher
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+)
+
+// SkillBuckets sorts the tickets of each region into fixed MMR bands as wide
+// as the queue's MaxRatingSpread (0-199, 200-399, ... for 200) and only
+// matches tickets of the same band. A ticket left over in its band moves up
+// to the next one once its widened rating window exceeds the band width.
+type SkillBuckets struct {
+	rules
+}
+
+func NewSkillBuckets(config queue.Config) *SkillBuckets {
+	return &SkillBuckets{rules{config: config}}
+}
+
+func (m *SkillBuckets) Match(tickets []*entities.Ticket, now time.Time) ([]entities.Match, []*entities.Ticket) {
+	log.Printf("[MATCHER] Bucketing %d tickets by skill", len(tickets))
+
+	matches, remaining := m.byRegion(tickets, now, func(eligible []*entities.Ticket) ([]entities.Match, []*entities.Ticket) {
+		return m.matchBuckets(eligible, now)
+	})
+
+	log.Printf("[MATCHER] Created %d total matches, %d players left over", len(matches), len(remaining))
+	return matches, remaining
+}
+
+func (m *SkillBuckets) matchBuckets(tickets []*entities.Ticket, now time.Time) ([]entities.Match, []*entities.Ticket) {
+	width := m.config.MaxRatingSpread
+	if width <= 0 {
+		return m.splitIntoMatches(tickets)
+	}
+
+	buckets := make(map[int][]*entities.Ticket)
+	for _, ticket := range tickets {
+		band := ticket.MMR() / width
+		buckets[band] = append(buckets[band], ticket)
+	}
+	bands := make([]int, 0, len(buckets))
+	for band := range buckets {
+		bands = append(bands, band)
+	}
+	sort.Ints(bands)
+
+	var matches, bandMatches []entities.Match
+	var leftovers, carried, left []*entities.Ticket
+
+	for i, band := range bands {
+		if i > 0 && band != bands[i-1]+1 {
+			// No adjacent band to move up to.
+			leftovers = append(leftovers, carried...)
+			carried = nil
+		}
+
+		// Carried tickets queued earlier than the band's own, so they go first.
+		candidates := append(carried, buckets[band]...)
+		bandMatches, left = m.splitIntoMatches(candidates)
+		matches = append(matches, bandMatches...)
+		log.Printf("[MATCHER] Skill band %d-%d: %d matches from %d tickets", band*width, (band+1)*width-1, len(bandMatches), len(candidates))
+
+		carried = nil
+		for _, ticket := range left {
+			if m.ratingSpread(ticket, now) > width {
+				carried = append(carried, ticket)
+			} else {
+				leftovers = append(leftovers, ticket)
+			}
+		}
+	}
+
+	return matches, append(leftovers, carried...)
+}
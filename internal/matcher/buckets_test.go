@@ -0,0 +1,45 @@
+package matcher
+
+import (
+	"testing"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+)
+
+func TestSkillBuckets(t *testing.T) {
+	runMatcherTests(t, func(c queue.Config) Matcher { return NewSkillBuckets(c) }, []matcherTest{
+		{
+			name:      "matches within a band",
+			tickets:   []*entities.Ticket{ticket("a", 150), ticket("c", 210), ticket("b", 190), ticket("d", 390), ticket("e", 450)},
+			matches:   []string{"[a,b]", "[c,d]"},
+			leftovers: []string{"e"},
+		},
+		{
+			name:      "close ratings across a band edge stay apart",
+			tickets:   []*entities.Ticket{ticket("a", 190), ticket("b", 210)},
+			leftovers: []string{"a", "b"},
+		},
+		{
+			name:    "leftover moves up a band once its window is wider",
+			tickets: []*entities.Ticket{waiting(ticket("a", 190), 15*time.Second), ticket("b", 210)},
+			matches: []string{"[a,b]"},
+		},
+		{
+			name:      "carried ticket stops at a gap between bands",
+			tickets:   []*entities.Ticket{waiting(ticket("a", 190), 15*time.Second), ticket("b", 610)},
+			leftovers: []string{"a", "b"},
+		},
+		{
+			name: "bands are formed per region",
+			tickets: []*entities.Ticket{
+				pinging(ticket("a", 150), map[string]int{"eu": 50}),
+				pinging(ticket("b", 160), map[string]int{"us": 50}),
+				pinging(ticket("c", 170), map[string]int{"eu": 50}),
+			},
+			matches:   []string{"eu[a,c]"},
+			leftovers: []string{"b"},
+		},
+	})
+}
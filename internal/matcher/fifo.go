@@ -0,0 +1,77 @@
+package matcher
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+)
+
+// FIFO groups the tickets of each region by rating with a sliding window and
+// fills matches in the order the tickets were queued.
+type FIFO struct {
+	rules
+}
+
+func NewFIFO(config queue.Config) *FIFO {
+	return &FIFO{rules{config: config}}
+}
+
+// Match widens both the latency and the rating bound with the time a ticket
+// has waited, as of now.
+func (m *FIFO) Match(tickets []*entities.Ticket, now time.Time) ([]entities.Match, []*entities.Ticket) {
+	log.Printf("[MATCHER] Creating optimal matches for %d players", len(tickets))
+
+	matches, remaining := m.byRegion(tickets, now, func(eligible []*entities.Ticket) ([]entities.Match, []*entities.Ticket) {
+		return m.groupByRating(eligible, now)
+	})
+
+	log.Printf("[MATCHER] Created %d total matches, %d players left over", len(matches), len(remaining))
+	return matches, remaining
+}
+
+// groupByRating groups tickets whose MMR lies within the rating spread and
+// splits each group into matches, returning the tickets left over. A group
+// is extended while the gap fits the wider window of the two tickets being
+// compared, so a long-waiting ticket can reach further.
+func (m *FIFO) groupByRating(tickets []*entities.Ticket, now time.Time) ([]entities.Match, []*entities.Ticket) {
+	if m.config.MaxRatingSpread <= 0 {
+		return m.splitIntoMatches(tickets)
+	}
+
+	// Stable sort keeps FIFO order between tickets with the same rating.
+	sorted := make([]*entities.Ticket, len(tickets))
+	copy(sorted, tickets)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].MMR() < sorted[j].MMR()
+	})
+
+	var matches []entities.Match
+	var leftovers []*entities.Ticket
+
+	for start := 0; start < len(sorted); {
+		first := sorted[start]
+		firstSpread := m.ratingSpread(first, now)
+
+		end := start + 1
+		for end < len(sorted) {
+			spread := max(firstSpread, m.ratingSpread(sorted[end], now))
+			if sorted[end].MMR()-first.MMR() > spread {
+				break
+			}
+			end++
+		}
+
+		group := sorted[start:end]
+		start = end
+		log.Printf("[MATCHER] Rating group %d-%d has %d tickets", group[0].MMR(), group[len(group)-1].MMR(), len(group))
+
+		groupMatches, groupLeftovers := m.splitIntoMatches(group)
+		matches = append(matches, groupMatches...)
+		leftovers = append(leftovers, groupLeftovers...)
+	}
+
+	return matches, leftovers
+}
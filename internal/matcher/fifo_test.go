@@ -0,0 +1,89 @@
+package matcher
+
+import (
+	"testing"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+)
+
+func TestFIFO(t *testing.T) {
+	maxPlayers := func(n int) func(*queue.Config) {
+		return func(c *queue.Config) { c.MaxPlayers = n }
+	}
+
+	runMatcherTests(t, func(c queue.Config) Matcher { return NewFIFO(c) }, []matcherTest{
+		{
+			name:      "groups by rating",
+			tickets:   []*entities.Ticket{ticket("a", 1000), ticket("c", 1500), ticket("e", 2500), ticket("b", 1100), ticket("d", 1550)},
+			matches:   []string{"[a,b]", "[c,d]"},
+			leftovers: []string{"e"},
+		},
+		{
+			name:      "window is anchored to the first ticket of a group",
+			tickets:   []*entities.Ticket{ticket("a", 1000), ticket("b", 1150), ticket("c", 1300)},
+			matches:   []string{"[a,b]"},
+			leftovers: []string{"c"},
+		},
+		{
+			name:      "fills matches in queue order",
+			config:    maxPlayers(3),
+			tickets:   []*entities.Ticket{ticket("a", 1000), ticket("b", 1000), ticket("c", 1000), ticket("d", 1000), ticket("e", 1000), ticket("f", 1000), ticket("g", 1000)},
+			matches:   []string{"[a,b,c]", "[d,e,f]"},
+			leftovers: []string{"g"},
+		},
+		{
+			name:      "rating window stays narrow for fresh tickets",
+			tickets:   []*entities.Ticket{ticket("a", 1000), ticket("b", 1400)},
+			leftovers: []string{"a", "b"},
+		},
+		{
+			name:    "rating window widens while waiting",
+			tickets: []*entities.Ticket{waiting(ticket("a", 1000), time.Minute), ticket("b", 1400)},
+			matches: []string{"[a,b]"},
+		},
+		{
+			name: "matches each region under the latency bound",
+			tickets: []*entities.Ticket{
+				pinging(ticket("a", 1000), map[string]int{"eu": 50}),
+				pinging(ticket("b", 1000), map[string]int{"eu": 60}),
+				pinging(ticket("c", 1000), map[string]int{"us": 40}),
+				pinging(ticket("d", 1000), map[string]int{"eu": 200}),
+				ticket("e", 1000),
+				ticket("f", 1000),
+			},
+			matches:   []string{"[e,f]", "eu[a,b]"},
+			leftovers: []string{"c", "d"},
+		},
+		{
+			name: "latency bound widens while waiting",
+			tickets: []*entities.Ticket{
+				pinging(ticket("a", 1000), map[string]int{"eu": 50}),
+				pinging(waiting(ticket("d", 1000), time.Minute), map[string]int{"eu": 200}),
+			},
+			matches: []string{"eu[a,d]"},
+		},
+		{
+			name: "party plays where all members can",
+			tickets: []*entities.Ticket{
+				pinging(ticket("a", 1000, "a2"), map[string]int{"eu": 50, "us": 50}),
+				pinging(ticket("b", 1000), map[string]int{"us": 60}),
+			},
+			matches: []string{"us[a,a2,b]"},
+		},
+		{
+			name:    "parties are kept together",
+			config:  maxPlayers(4),
+			tickets: []*entities.Ticket{ticket("a", 1000, "a2", "a3"), ticket("c", 1000, "c2"), ticket("b", 1000), ticket("d", 1000)},
+			matches: []string{"[a,a2,a3,b]", "[c,c2,d]"},
+		},
+		{
+			name:      "party larger than a match waits",
+			config:    maxPlayers(2),
+			tickets:   []*entities.Ticket{ticket("a", 1000, "a2", "a3"), ticket("b", 1000), ticket("c", 1000)},
+			matches:   []string{"[b,c]"},
+			leftovers: []string{"a"},
+		},
+	})
+}
@@ -0,0 +1,91 @@
+package matcher
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+)
+
+// LatencyClusters puts every ticket in the region it has the lowest ping to
+// and fills matches from the lowest pings up, so players with similar
+// latency play together. Ratings are not considered.
+type LatencyClusters struct {
+	rules
+}
+
+func NewLatencyClusters(config queue.Config) *LatencyClusters {
+	return &LatencyClusters{rules{config: config}}
+}
+
+func (m *LatencyClusters) Match(tickets []*entities.Ticket, now time.Time) ([]entities.Match, []*entities.Ticket) {
+	log.Printf("[MATCHER] Clustering %d tickets by latency", len(tickets))
+
+	clusters := make(map[string][]*entities.Ticket)
+	pings := make(map[*entities.Ticket]int, len(tickets))
+	var leftovers []*entities.Ticket
+
+	for _, ticket := range tickets {
+		region, ping, ok := m.bestRegion(ticket, now)
+		if !ok {
+			leftovers = append(leftovers, ticket)
+			continue
+		}
+		clusters[region] = append(clusters[region], ticket)
+		pings[ticket] = ping
+	}
+
+	regions := make([]string, 0, len(clusters))
+	for region := range clusters {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	var matches []entities.Match
+	for _, region := range regions {
+		cluster := clusters[region]
+		// Stable sort keeps FIFO order between tickets with the same ping.
+		sort.SliceStable(cluster, func(i, j int) bool {
+			return pings[cluster[i]] < pings[cluster[j]]
+		})
+
+		clusterMatches, left := m.splitIntoMatches(cluster)
+		for i := range clusterMatches {
+			clusterMatches[i].Region = region
+		}
+		log.Printf("[MATCHER] Region %q: %d matches from %d tickets", region, len(clusterMatches), len(cluster))
+
+		matches = append(matches, clusterMatches...)
+		leftovers = append(leftovers, left...)
+	}
+
+	log.Printf("[MATCHER] Created %d total matches, %d players left over", len(matches), len(leftovers))
+	return matches, leftovers
+}
+
+// bestRegion returns the region with the lowest ping the ticket may play in.
+// Tickets without region pings form the "" cluster and are ordered by the
+// worst ping of their players.
+func (m *LatencyClusters) bestRegion(ticket *entities.Ticket, now time.Time) (string, int, bool) {
+	pings := ticket.Pings()
+	if len(pings) == 0 {
+		worst := 0
+		for _, player := range ticket.Players() {
+			worst = max(worst, player.Ping)
+		}
+		return "", worst, true
+	}
+
+	best, bestPing, found := "", 0, false
+	for region, ping := range pings {
+		if !m.canPlayIn(ticket, region, now) {
+			continue
+		}
+		if !found || ping < bestPing || (ping == bestPing && region < best) {
+			best, bestPing, found = region, ping, true
+		}
+	}
+	return best, bestPing, found
+}
@@ -0,0 +1,61 @@
+package matcher
+
+import (
+	"testing"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+)
+
+func TestLatencyClusters(t *testing.T) {
+	runMatcherTests(t, func(c queue.Config) Matcher { return NewLatencyClusters(c) }, []matcherTest{
+		{
+			name: "tickets play in their best region",
+			tickets: []*entities.Ticket{
+				pinging(ticket("a", 1000), map[string]int{"eu": 30, "us": 100}),
+				pinging(ticket("b", 2000), map[string]int{"eu": 80, "us": 20}),
+				pinging(ticket("c", 3000), map[string]int{"eu": 40}),
+				pinging(ticket("d", 4000), map[string]int{"us": 50}),
+			},
+			matches: []string{"eu[a,c]", "us[b,d]"},
+		},
+		{
+			name:   "lowest pings are matched first",
+			config: func(c *queue.Config) { c.MaxPlayers = 2 },
+			tickets: []*entities.Ticket{
+				pinging(ticket("a", 1000), map[string]int{"eu": 90}),
+				pinging(ticket("b", 1000), map[string]int{"eu": 10}),
+				pinging(ticket("c", 1000), map[string]int{"eu": 50}),
+			},
+			matches:   []string{"eu[b,c]"},
+			leftovers: []string{"a"},
+		},
+		{
+			name: "regions over the latency bound are skipped",
+			tickets: []*entities.Ticket{
+				pinging(ticket("a", 1000), map[string]int{"eu": 200, "us": 100}),
+				pinging(ticket("b", 1000), map[string]int{"us": 120}),
+				pinging(ticket("c", 1000), map[string]int{"eu": 400}),
+			},
+			matches:   []string{"us[a,b]"},
+			leftovers: []string{"c"},
+		},
+		{
+			name: "party is placed by the worst ping of its members",
+			tickets: []*entities.Ticket{
+				func() *entities.Ticket {
+					party := pinging(ticket("a", 1000, "a2"), map[string]int{"eu": 20, "us": 90})
+					party.Members[0].Pings = map[string]int{"eu": 120, "us": 60}
+					return party
+				}(),
+				pinging(ticket("b", 1000), map[string]int{"us": 80}),
+			},
+			matches: []string{"us[a,a2,b]"},
+		},
+		{
+			name:    "tickets without pings cluster together",
+			tickets: []*entities.Ticket{ticket("e", 1000), ticket("f", 3000)},
+			matches: []string{"[e,f]"},
+		},
+	})
+}
@@ -0,0 +1,31 @@
+package matcher
+
+import (
+	"fmt"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+)
+
+// Matcher decides who plays with whom. It gets the tickets claimed from a
+// queue's pool, in FIFO order, and returns the matches it formed as of now
+// plus the tickets it left over. Loading, requeueing and publishing stay with
+// the worker.
+type Matcher interface {
+	Match(tickets []*entities.Ticket, now time.Time) ([]entities.Match, []*entities.Ticket)
+}
+
+// New returns the strategy the queue is configured with.
+func New(config queue.Config) (Matcher, error) {
+	switch config.Matcher {
+	case queue.MatcherFIFO, "":
+		return NewFIFO(config), nil
+	case queue.MatcherSkillBuckets:
+		return NewSkillBuckets(config), nil
+	case queue.MatcherLatency:
+		return NewLatencyClusters(config), nil
+	default:
+		return nil, fmt.Errorf("queue %s: unknown matcher %q", config.Name, config.Matcher)
+	}
+}
@@ -0,0 +1,126 @@
+package matcher
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+)
+
+// now is the time every test pass runs at.
+var now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// ticket returns a queued ticket for id at the given rating, with a party of
+// the members, all rated the same.
+func ticket(id string, mmr int, members ...string) *entities.Ticket {
+	t := &entities.Ticket{
+		ID:        id,
+		Player:    entities.Player{ID: id, MMR: mmr},
+		Status:    entities.TicketQueued,
+		CreatedAt: now,
+	}
+	for _, member := range members {
+		t.Members = append(t.Members, entities.Player{ID: member, MMR: mmr})
+	}
+	return t
+}
+
+// waiting makes the ticket look queued for d as of now.
+func waiting(t *entities.Ticket, d time.Duration) *entities.Ticket {
+	t.CreatedAt = now.Add(-d)
+	return t
+}
+
+// pinging sets the region pings of everyone on the ticket.
+func pinging(t *entities.Ticket, pings map[string]int) *entities.Ticket {
+	t.Player.Pings = pings
+	for i := range t.Members {
+		t.Members[i].Pings = pings
+	}
+	return t
+}
+
+// describe renders matches as "region[player,...]" with the players sorted,
+// and leftovers as sorted ticket IDs.
+func describe(matches []entities.Match, leftovers []*entities.Ticket) ([]string, []string) {
+	formed := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids := make([]string, 0, len(match.Players))
+		for _, player := range match.Players {
+			ids = append(ids, player.ID)
+		}
+		slices.Sort(ids)
+		formed = append(formed, fmt.Sprintf("%s[%s]", match.Region, strings.Join(ids, ",")))
+	}
+
+	left := make([]string, 0, len(leftovers))
+	for _, t := range leftovers {
+		left = append(left, t.ID)
+	}
+	slices.Sort(left)
+	return formed, left
+}
+
+type matcherTest struct {
+	name      string
+	config    func(*queue.Config)
+	tickets   []*entities.Ticket
+	matches   []string
+	leftovers []string
+}
+
+// runMatcherTests runs every case through the matcher built by newMatcher.
+func runMatcherTests(t *testing.T, newMatcher func(queue.Config) Matcher, tests []matcherTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := queue.Default("test")
+			if tt.config != nil {
+				tt.config(&config)
+			}
+
+			matches, leftovers := newMatcher(config).Match(tt.tickets, now)
+			formed, left := describe(matches, leftovers)
+			if !slices.Equal(formed, tt.matches) {
+				t.Errorf("matches = %v, want %v", formed, tt.matches)
+			}
+			if !slices.Equal(left, tt.leftovers) {
+				t.Errorf("leftovers = %v, want %v", left, tt.leftovers)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		matcher string
+		want    Matcher
+	}{
+		{"", &FIFO{}},
+		{queue.MatcherFIFO, &FIFO{}},
+		{queue.MatcherSkillBuckets, &SkillBuckets{}},
+		{queue.MatcherLatency, &LatencyClusters{}},
+	}
+	for _, tt := range tests {
+		config := queue.Default("test")
+		config.Matcher = tt.matcher
+		m, err := New(config)
+		if err != nil {
+			t.Fatalf("New(%q): %v", tt.matcher, err)
+		}
+		if got, want := fmt.Sprintf("%T", m), fmt.Sprintf("%T", tt.want); got != want {
+			t.Errorf("New(%q) = %s, want %s", tt.matcher, got, want)
+		}
+	}
+
+	config := queue.Default("test")
+	config.Matcher = "random"
+	if _, err := New(config); err == nil {
+		t.Error("New accepted an unknown matcher")
+	}
+}
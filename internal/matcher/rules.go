@@ -0,0 +1,225 @@
+package matcher
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+
+	"github.com/google/uuid"
+)
+
+// rules holds what every strategy shares: the queue's windows and how a
+// group of tickets is cut into matches.
+type rules struct {
+	config queue.Config
+}
+
+// ratingSpread is the MMR window of a ticket as of now.
+func (r rules) ratingSpread(ticket *entities.Ticket, now time.Time) int {
+	w := r.config.Widening
+	return w.Expand(r.config.MaxRatingSpread, w.RatingStep, w.MaxRatingSpread, now.Sub(ticket.CreatedAt))
+}
+
+// latencyBound is the highest ping a ticket accepts as of now.
+func (r rules) latencyBound(ticket *entities.Ticket, now time.Time) int {
+	w := r.config.Widening
+	return w.Expand(r.config.MaxLatency, w.LatencyStep, w.MaxLatency, now.Sub(ticket.CreatedAt))
+}
+
+// canPlayIn reports whether the ticket may be placed in a match hosted in
+// region. The empty region stands for tickets without region pings.
+func (r rules) canPlayIn(ticket *entities.Ticket, region string, now time.Time) bool {
+	if region == "" {
+		return len(ticket.Pings()) == 0
+	}
+
+	ping, ok := ticket.Pings()[region]
+	if !ok {
+		return false
+	}
+
+	maxLatency := r.latencyBound(ticket, now)
+	return maxLatency <= 0 || ping <= maxLatency
+}
+
+// byRegion picks, one region at a time, the tickets that can play there under
+// their latency bound and lets group form matches out of them. Tickets
+// without region pings are only matched with each other. It returns the
+// tickets that could not be placed in any match.
+func (r rules) byRegion(tickets []*entities.Ticket, now time.Time, group func([]*entities.Ticket) ([]entities.Match, []*entities.Ticket)) ([]entities.Match, []*entities.Ticket) {
+	var matches []entities.Match
+	remaining := tickets
+
+	for progress := true; progress; {
+		progress = false
+
+		for _, region := range r.regionsBySize(remaining, now) {
+			eligible := make([]*entities.Ticket, 0, len(remaining))
+			eligiblePlayers := 0
+			for _, ticket := range remaining {
+				if r.canPlayIn(ticket, region, now) {
+					eligible = append(eligible, ticket)
+					eligiblePlayers += ticket.Size()
+				}
+			}
+			if eligiblePlayers < r.config.MinPlayers {
+				continue
+			}
+
+			regionMatches, _ := group(eligible)
+			if len(regionMatches) == 0 {
+				continue
+			}
+
+			for i := range regionMatches {
+				regionMatches[i].Region = region
+			}
+			log.Printf("[MATCHER] Region %q: %d matches from %d eligible players", region, len(regionMatches), eligiblePlayers)
+			matches = append(matches, regionMatches...)
+			remaining = unmatched(remaining, regionMatches)
+
+			// Region counts changed, start over from the largest one.
+			progress = true
+			break
+		}
+	}
+
+	return matches, remaining
+}
+
+// regionsBySize lists the regions tickets can play in, the ones with the most
+// eligible tickets first.
+func (r rules) regionsBySize(tickets []*entities.Ticket, now time.Time) []string {
+	counts := make(map[string]int)
+	for _, ticket := range tickets {
+		pings := ticket.Pings()
+		if len(pings) == 0 {
+			counts[""]++
+			continue
+		}
+		for region := range pings {
+			if r.canPlayIn(ticket, region, now) {
+				counts[region]++
+			}
+		}
+	}
+
+	regions := make([]string, 0, len(counts))
+	for region := range counts {
+		regions = append(regions, region)
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		if counts[regions[i]] != counts[regions[j]] {
+			return counts[regions[i]] > counts[regions[j]]
+		}
+		return regions[i] < regions[j]
+	})
+	return regions
+}
+
+// splitIntoMatches cuts tickets into matches in order and returns the
+// remainder that is too small for another match. Tickets are never split, so
+// a party that doesn't fit the current match waits for the next one.
+func (r rules) splitIntoMatches(tickets []*entities.Ticket) ([]entities.Match, []*entities.Ticket) {
+	if r.config.TeamsEnabled() {
+		return r.splitIntoTeamMatches(tickets)
+	}
+
+	var matches []entities.Match
+	remaining := tickets
+
+	for {
+		totalPlayers := 0
+		for _, ticket := range remaining {
+			totalPlayers += ticket.Size()
+		}
+		if totalPlayers < r.config.MinPlayers {
+			break
+		}
+
+		matchSize := r.calculateOptimalMatchSize(totalPlayers)
+		log.Printf("[MATCHER] Optimal match size for %d remaining players: %d", totalPlayers, matchSize)
+
+		var matchPlayers []entities.Player
+		var skipped []*entities.Ticket
+		for _, ticket := range remaining {
+			if len(matchPlayers)+ticket.Size() > matchSize {
+				skipped = append(skipped, ticket)
+				continue
+			}
+			matchPlayers = append(matchPlayers, ticket.Players()...)
+		}
+
+		if len(matchPlayers) < r.config.MinPlayers {
+			break
+		}
+		remaining = skipped
+
+		match := entities.Match{
+			MatchID:   generateMatchID(),
+			Players:   matchPlayers,
+			CreatedAt: time.Now(),
+			Queue:     r.config.Name,
+		}
+		matches = append(matches, match)
+
+		log.Printf("[MATCHER] Match %s created with %d players", match.MatchID, len(matchPlayers))
+	}
+
+	return matches, remaining
+}
+
+func (r rules) calculateOptimalMatchSize(totalPlayers int) int {
+	maxPlayers := r.config.MaxPlayers
+	if totalPlayers <= maxPlayers {
+		log.Printf("[MATCHER] Using all %d players (within max limit)", totalPlayers)
+		return totalPlayers
+	}
+
+	// The split sizes only apply to queues whose player range allows them.
+	fits := func(size int) bool {
+		return size >= r.config.MinPlayers && size <= maxPlayers
+	}
+
+	if totalPlayers >= 24 && fits(12) {
+		log.Printf("[MATCHER] Large group (%d players) - creating match of 12", totalPlayers)
+		return 12
+	} else if totalPlayers >= 18 && fits(9) {
+		log.Printf("[MATCHER] Medium group (%d players) - creating match of 9", totalPlayers)
+		return 9
+	} else {
+		log.Printf("[MATCHER] Small group (%d players) - creating match of %d", totalPlayers, maxPlayers)
+		return maxPlayers
+	}
+}
+
+// unmatched returns the tickets none of whose players is in matches.
+func unmatched(tickets []*entities.Ticket, matches []entities.Match) []*entities.Ticket {
+	matched := make(map[string]bool)
+	for _, match := range matches {
+		for _, player := range match.Players {
+			matched[player.ID] = true
+		}
+	}
+
+	left := make([]*entities.Ticket, 0, len(tickets))
+	for _, ticket := range tickets {
+		if !matched[ticket.Player.ID] {
+			left = append(left, ticket)
+		}
+	}
+	return left
+}
+
+// generateMatchID returns a UUIDv7-based ID: unique across workers and
+// ordered by creation time.
+func generateMatchID() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		id = uuid.New()
+	}
+	return "match_" + id.String()
+}
@@ -0,0 +1,86 @@
+package matcher
+
+import (
+	"encoding/binary"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGenerateMatchIDConcurrent(t *testing.T) {
+	const (
+		goroutines = 16
+		perRoutine = 500
+	)
+
+	// generate runs the goroutines and returns the IDs of each, in the
+	// order it generated them.
+	generate := func() [][]string {
+		ids := make([][]string, goroutines)
+		var wg sync.WaitGroup
+		for g := range ids {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < perRoutine; i++ {
+					ids[g] = append(ids[g], generateMatchID())
+				}
+			}()
+		}
+		wg.Wait()
+		return ids
+	}
+
+	start := time.Now()
+	first := generate()
+	second := generate()
+	end := time.Now()
+
+	seen := make(map[string]bool, 2*goroutines*perRoutine)
+	var firstMax string
+	for round, ids := range [][][]string{first, second} {
+		for _, routine := range ids {
+			for i, id := range routine {
+				if seen[id] {
+					t.Fatalf("duplicate match ID %s", id)
+				}
+				seen[id] = true
+
+				if i > 0 && id <= routine[i-1] {
+					t.Fatalf("%s generated after %s sorts before it", id, routine[i-1])
+				}
+				if round == 0 && id > firstMax {
+					firstMax = id
+				}
+				if round == 1 && id <= firstMax {
+					t.Fatalf("%s generated after %s sorts before it", id, firstMax)
+				}
+
+				created := matchIDTime(t, id)
+				if created.Before(start.Truncate(time.Millisecond)) || created.After(end) {
+					t.Fatalf("%s carries time %s outside of %s..%s", id, created, start, end)
+				}
+			}
+		}
+	}
+}
+
+// matchIDTime returns the creation time embedded in a UUIDv7 match ID.
+func matchIDTime(t *testing.T, matchID string) time.Time {
+	t.Helper()
+
+	id, err := uuid.Parse(strings.TrimPrefix(matchID, "match_"))
+	if err != nil {
+		t.Fatalf("parse %s: %v", matchID, err)
+	}
+	if id.Version() != 7 {
+		t.Fatalf("%s is UUID version %d, want 7", matchID, id.Version())
+	}
+
+	var ms [8]byte
+	copy(ms[2:], id[:6])
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(ms[:])))
+}
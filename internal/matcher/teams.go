@@ -0,0 +1,198 @@
+package matcher
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+)
+
+// maxBalanceSwaps bounds the improvement pass of balanceTeams.
+const maxBalanceSwaps = 100
+
+// splitIntoTeamMatches fills matches of exactly TeamCount x TeamSize players
+// in FIFO order. A party always lands on a single team, so it is skipped for
+// the current match if no team has room for it.
+func (r rules) splitIntoTeamMatches(tickets []*entities.Ticket) ([]entities.Match, []*entities.Ticket) {
+	capacity := r.config.TeamCount * r.config.TeamSize
+
+	var matches []entities.Match
+	remaining := tickets
+
+	for {
+		free := make([]int, r.config.TeamCount)
+		for i := range free {
+			free[i] = r.config.TeamSize
+		}
+
+		filled := 0
+		var picked, skipped []*entities.Ticket
+		for _, ticket := range remaining {
+			team := roomiestTeam(free)
+			if filled == capacity || free[team] < ticket.Size() {
+				skipped = append(skipped, ticket)
+				continue
+			}
+			free[team] -= ticket.Size()
+			filled += ticket.Size()
+			picked = append(picked, ticket)
+		}
+
+		if filled < capacity {
+			break
+		}
+		remaining = skipped
+
+		teams := r.balanceTeams(picked)
+		match := entities.Match{
+			MatchID:   generateMatchID(),
+			CreatedAt: time.Now(),
+			Queue:     r.config.Name,
+		}
+		for _, teamTickets := range teams {
+			var team entities.Team
+			for _, ticket := range teamTickets {
+				team.Players = append(team.Players, ticket.Players()...)
+			}
+			team.MMR = teamRating(teamTickets) / len(team.Players)
+			match.Teams = append(match.Teams, team)
+			match.Players = append(match.Players, team.Players...)
+		}
+		matches = append(matches, match)
+
+		log.Printf("[MATCHER] Match %s created with %d teams of %d players", match.MatchID, r.config.TeamCount, r.config.TeamSize)
+	}
+
+	return matches, remaining
+}
+
+// balanceTeams spreads the tickets over the teams so their total ratings are
+// as close as possible: biggest parties and strongest players are placed
+// first on the weakest team with room, then equally sized tickets are swapped
+// between teams while that narrows the gap.
+func (r rules) balanceTeams(tickets []*entities.Ticket) [][]*entities.Ticket {
+	sorted := make([]*entities.Ticket, len(tickets))
+	copy(sorted, tickets)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Size() != sorted[j].Size() {
+			return sorted[i].Size() > sorted[j].Size()
+		}
+		return sorted[i].MMR() > sorted[j].MMR()
+	})
+
+	teams := make([][]*entities.Ticket, r.config.TeamCount)
+	free := make([]int, r.config.TeamCount)
+	ratings := make([]int, r.config.TeamCount)
+	for i := range free {
+		free[i] = r.config.TeamSize
+	}
+
+	for _, ticket := range sorted {
+		best := -1
+		for i := range teams {
+			if free[i] < ticket.Size() {
+				continue
+			}
+			if best == -1 || ratings[i] < ratings[best] {
+				best = i
+			}
+		}
+		if best == -1 {
+			// Rating-first placement painted itself into a corner; fall back
+			// to the placement used when the tickets were picked.
+			return r.packTeams(tickets)
+		}
+		teams[best] = append(teams[best], ticket)
+		free[best] -= ticket.Size()
+		ratings[best] += ticketRating(ticket)
+	}
+
+	for swaps := 0; swaps < maxBalanceSwaps; swaps++ {
+		if !improveBalance(teams, ratings) {
+			break
+		}
+	}
+
+	return teams
+}
+
+// packTeams places tickets in order on the team with the most free slots,
+// the same way splitIntoTeamMatches picked them.
+func (r rules) packTeams(tickets []*entities.Ticket) [][]*entities.Ticket {
+	teams := make([][]*entities.Ticket, r.config.TeamCount)
+	free := make([]int, r.config.TeamCount)
+	for i := range free {
+		free[i] = r.config.TeamSize
+	}
+
+	for _, ticket := range tickets {
+		team := roomiestTeam(free)
+		teams[team] = append(teams[team], ticket)
+		free[team] -= ticket.Size()
+	}
+	return teams
+}
+
+// improveBalance performs the first swap of two equally sized tickets that
+// narrows the rating gap between their teams and reports whether it found one.
+func improveBalance(teams [][]*entities.Ticket, ratings []int) bool {
+	for a := range teams {
+		for b := a + 1; b < len(teams); b++ {
+			gap := abs(ratings[a] - ratings[b])
+
+			for i, ta := range teams[a] {
+				for j, tb := range teams[b] {
+					if ta.Size() != tb.Size() {
+						continue
+					}
+
+					delta := ticketRating(tb) - ticketRating(ta)
+					if abs(ratings[a]+delta-(ratings[b]-delta)) >= gap {
+						continue
+					}
+
+					teams[a][i], teams[b][j] = tb, ta
+					ratings[a] += delta
+					ratings[b] -= delta
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func roomiestTeam(free []int) int {
+	best := 0
+	for i := range free {
+		if free[i] > free[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// ticketRating is the summed MMR of everyone on the ticket.
+func ticketRating(ticket *entities.Ticket) int {
+	total := 0
+	for _, player := range ticket.Players() {
+		total += player.MMR
+	}
+	return total
+}
+
+func teamRating(tickets []*entities.Ticket) int {
+	total := 0
+	for _, ticket := range tickets {
+		total += ticketRating(ticket)
+	}
+	return total
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,134 @@
+package matcher
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+)
+
+func teamsRules(count, size int) rules {
+	config := queue.Default("test")
+	config.TeamCount, config.TeamSize = count, size
+	config.MinPlayers, config.MaxPlayers = count*size, count*size
+	return rules{config: config}
+}
+
+// teamIDs renders each team as its sorted ticket IDs.
+func teamIDs(teams [][]*entities.Ticket) []string {
+	rendered := make([]string, 0, len(teams))
+	for _, team := range teams {
+		ids := make([]string, 0, len(team))
+		for _, t := range team {
+			ids = append(ids, t.ID)
+		}
+		slices.Sort(ids)
+		rendered = append(rendered, strings.Join(ids, ","))
+	}
+	return rendered
+}
+
+func TestBalanceTeams(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		size    int
+		tickets []*entities.Ticket
+		want    []string
+	}{
+		{
+			name:    "strongest players spread over the teams",
+			count:   2,
+			size:    2,
+			tickets: []*entities.Ticket{ticket("a", 2000), ticket("b", 1800), ticket("c", 1200), ticket("d", 1000)},
+			want:    []string{"a,d", "b,c"},
+		},
+		{
+			name:    "parties are placed first and kept together",
+			count:   2,
+			size:    3,
+			tickets: []*entities.Ticket{ticket("s", 2000), ticket("t", 1400), ticket("u", 1000), ticket("v", 900), ticket("p", 1500, "p2")},
+			want:    []string{"p,u", "s,t,v"},
+		},
+		{
+			name:    "equally sized tickets are swapped to narrow the gap",
+			count:   2,
+			size:    3,
+			tickets: []*entities.Ticket{ticket("p", 100, "p2"), ticket("a", 1000), ticket("b", 900), ticket("c", 800), ticket("e", 100)},
+			want:    []string{"a,p", "b,c,e"},
+		},
+		{
+			name:  "falls back to packing when balancing runs out of room",
+			count: 2,
+			size:  7,
+			tickets: []*entities.Ticket{
+				ticket("a", 1000, "a2", "a3", "a4"),
+				ticket("b", 1000, "b2", "b3"),
+				ticket("d", 1000, "d2"),
+				ticket("c", 1000, "c2", "c3"),
+				ticket("e", 1000, "e2"),
+			},
+			want: []string{"a,c", "b,d,e"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := teamsRules(tt.count, tt.size)
+			teams := r.balanceTeams(tt.tickets)
+			if got := teamIDs(teams); !slices.Equal(got, tt.want) {
+				t.Errorf("teams = %v, want %v", got, tt.want)
+			}
+			for i, team := range teams {
+				players := 0
+				for _, t := range team {
+					players += t.Size()
+				}
+				if players != tt.size {
+					t.Errorf("team %d has %d players, want %d", i, players, tt.size)
+				}
+			}
+		})
+	}
+}
+
+func TestPackTeams(t *testing.T) {
+	r := teamsRules(2, 3)
+	// Placed in order on the roomiest team, ratings aside.
+	tickets := []*entities.Ticket{ticket("a", 1000, "a2"), ticket("b", 3000), ticket("c", 1000), ticket("d", 100)}
+	if got, want := teamIDs(r.packTeams(tickets)), []string{"a,d", "b,c"}; !slices.Equal(got, want) {
+		t.Errorf("teams = %v, want %v", got, want)
+	}
+}
+
+func TestSplitIntoTeamMatches(t *testing.T) {
+	r := teamsRules(2, 2)
+	tickets := []*entities.Ticket{ticket("a", 2000), ticket("p", 1500, "p2", "p3"), ticket("b", 1800), ticket("c", 1200), ticket("d", 1000), ticket("e", 1000)}
+
+	matches, leftovers := r.splitIntoTeamMatches(tickets)
+	if len(matches) != 1 {
+		t.Fatalf("formed %d matches, want 1", len(matches))
+	}
+	match := matches[0]
+	if len(match.Teams) != 2 || len(match.Players) != 4 {
+		t.Fatalf("match = %+v, want 2 teams of 2", match)
+	}
+	for i, want := range [][]string{{"a", "d"}, {"b", "c"}} {
+		team := match.Teams[i]
+		var ids []string
+		for _, player := range team.Players {
+			ids = append(ids, player.ID)
+		}
+		slices.Sort(ids)
+		if !slices.Equal(ids, want) || team.MMR != 1500 {
+			t.Errorf("team %d = %v rated %d, want %v rated 1500", i, ids, team.MMR, want)
+		}
+	}
+
+	// The party of three fits no team of two.
+	_, left := describe(nil, leftovers)
+	if want := []string{"e", "p"}; !slices.Equal(left, want) {
+		t.Errorf("leftovers = %v, want %v", left, want)
+	}
+}
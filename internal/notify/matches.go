@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"log"
+	"sync"
+
+	"matchmaker-nats/internal/broker"
+	"matchmaker-nats/internal/entities"
+)
+
+// MatchNotifier fans the matches formed by the workers out to the clients
+// waiting in this API instance. It holds a single broker subscription for
+// all players instead of one per waiting client.
+type MatchNotifier struct {
+	mu        sync.Mutex
+	listeners map[string]map[chan entities.Match]struct{}
+	sub       broker.Subscription
+}
+
+func NewMatchNotifier(b broker.Broker) (*MatchNotifier, error) {
+	n := &MatchNotifier{
+		listeners: make(map[string]map[chan entities.Match]struct{}),
+	}
+
+	sub, err := b.SubscribeMatches(n.handle)
+	if err != nil {
+		return nil, err
+	}
+	n.sub = sub
+
+	log.Printf("[NOTIFY] Listening for match events")
+	return n, nil
+}
+
+// Subscribe returns a channel receiving the matches of playerID formed from
+// now on, and a function that releases it.
+func (n *MatchNotifier) Subscribe(playerID string) (<-chan entities.Match, func()) {
+	ch := make(chan entities.Match, 1)
+
+	n.mu.Lock()
+	if n.listeners[playerID] == nil {
+		n.listeners[playerID] = make(map[chan entities.Match]struct{})
+	}
+	n.listeners[playerID][ch] = struct{}{}
+	n.mu.Unlock()
+
+	return ch, func() {
+		n.mu.Lock()
+		delete(n.listeners[playerID], ch)
+		if len(n.listeners[playerID]) == 0 {
+			delete(n.listeners, playerID)
+		}
+		n.mu.Unlock()
+	}
+}
+
+func (n *MatchNotifier) Close() error {
+	return n.sub.Unsubscribe()
+}
+
+func (n *MatchNotifier) handle(match *entities.Match) {
+	for _, player := range match.Players {
+		n.mu.Lock()
+		chans := make([]chan entities.Match, 0, len(n.listeners[player.ID]))
+		for ch := range n.listeners[player.ID] {
+			chans = append(chans, ch)
+		}
+		n.mu.Unlock()
+
+		for _, ch := range chans {
+			select {
+			case ch <- *match:
+			default:
+				// The listener already has a match waiting; a player is only
+				// in one match per ticket so dropping is safe.
+			}
+		}
+	}
+}
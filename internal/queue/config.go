@@ -0,0 +1,128 @@
+package queue
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultName is the queue used when no configuration file is given.
+	DefaultName = "default"
+
+	DefaultMinPlayers = 2
+	DefaultMaxPlayers = 16
+	// DefaultMaxRatingSpread is the widest MMR gap allowed inside one match.
+	DefaultMaxRatingSpread = 200
+	// DefaultMaxLatency is the highest ping (ms) to the match region a
+	// player may have.
+	DefaultMaxLatency = 150
+)
+
+// Matching strategies a queue can use, see the matcher package.
+const (
+	// MatcherFIFO groups tickets by rating with a sliding window.
+	MatcherFIFO = "fifo"
+	// MatcherSkillBuckets only matches tickets in the same fixed MMR band.
+	MatcherSkillBuckets = "skill_buckets"
+	// MatcherLatency clusters tickets by their best region and ping.
+	MatcherLatency = "latency"
+)
+
+// Config holds the matching rules of one queue (game mode).
+type Config struct {
+	Name string `json:"name"`
+	// Matcher selects the matching strategy; MatcherFIFO by default.
+	Matcher string `json:"matcher"`
+
+	MinPlayers int `json:"min_players"`
+	MaxPlayers int `json:"max_players"`
+	// MaxRatingSpread bounds the difference between the highest and lowest
+	// MMR in a match. Zero or less disables rating grouping (pure FIFO).
+	MaxRatingSpread int `json:"max_rating_spread"`
+	// MaxLatency bounds a player's ping to the region a match is hosted in.
+	// Zero or less accepts any region the player reported.
+	MaxLatency int `json:"max_latency_ms"`
+	// Widening relaxes both bounds for tickets that have waited a while.
+	Widening Widening `json:"widening"`
+	// TeamCount teams of TeamSize players make up a match when both are set
+	// (e.g. 2x5); otherwise matches are a flat list of MinPlayers..MaxPlayers.
+	TeamCount int `json:"team_count"`
+	TeamSize  int `json:"team_size"`
+	// Tick schedules the matchmaking passes over the pool.
+	Tick Tick `json:"tick"`
+	// Webhook, when set, delivers the queue's matches to an HTTP endpoint.
+	Webhook *Webhook `json:"webhook,omitempty"`
+}
+
+func Default(name string) Config {
+	return Config{
+		Name:            name,
+		Matcher:         MatcherFIFO,
+		MinPlayers:      DefaultMinPlayers,
+		MaxPlayers:      DefaultMaxPlayers,
+		MaxRatingSpread: DefaultMaxRatingSpread,
+		MaxLatency:      DefaultMaxLatency,
+		Widening: Widening{
+			Schedule:        WidenStep,
+			Interval:        15 * time.Second,
+			RatingStep:      50,
+			LatencyStep:     25,
+			MaxRatingSpread: 1000,
+			MaxLatency:      300,
+		},
+		Tick: Tick{
+			Interval: DefaultTickInterval,
+		},
+	}
+}
+
+func (c Config) TeamsEnabled() bool {
+	return c.TeamCount > 0 && c.TeamSize > 0
+}
+
+// TickThreshold is how many new tickets make a pass run before the next tick.
+func (c Config) TickThreshold() int {
+	if c.Tick.Threshold > 0 {
+		return c.Tick.Threshold
+	}
+	return c.MaxPlayers
+}
+
+// MaxPartySize is the largest ticket that can still be placed in a match.
+func (c Config) MaxPartySize() int {
+	if c.TeamsEnabled() {
+		return c.TeamSize
+	}
+	return c.MaxPlayers
+}
+
+func (c Config) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("queue name is required")
+	}
+	// The name becomes a NATS subject token and a JetStream consumer name.
+	if strings.ContainsAny(c.Name, ".*> \t") {
+		return fmt.Errorf("queue %q: name must not contain '.', '*', '>' or whitespace", c.Name)
+	}
+	if c.MinPlayers < 1 || c.MaxPlayers < c.MinPlayers {
+		return fmt.Errorf("queue %s: invalid player range %d-%d", c.Name, c.MinPlayers, c.MaxPlayers)
+	}
+	switch c.Matcher {
+	case MatcherFIFO, MatcherSkillBuckets, MatcherLatency:
+	default:
+		return fmt.Errorf("queue %s: unknown matcher %q", c.Name, c.Matcher)
+	}
+	if (c.TeamCount > 0) != (c.TeamSize > 0) {
+		return fmt.Errorf("queue %s: team_count and team_size must be set together", c.Name)
+	}
+	if err := c.Tick.Validate(); err != nil {
+		return fmt.Errorf("queue %s: %w", c.Name, err)
+	}
+	if c.Webhook != nil {
+		if err := c.Webhook.Validate(); err != nil {
+			return fmt.Errorf("queue %s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
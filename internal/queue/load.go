@@ -0,0 +1,46 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type file struct {
+	Queues []json.RawMessage `json:"queues"`
+}
+
+// Load reads the queue definitions from a JSON file. Fields a queue leaves
+// out keep the values of Default.
+func Load(path string) ([]Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(f.Queues) == 0 {
+		return nil, fmt.Errorf("%s defines no queues", path)
+	}
+
+	seen := make(map[string]bool, len(f.Queues))
+	queues := make([]Config, 0, len(f.Queues))
+	for _, raw := range f.Queues {
+		config := Default("")
+		if err := json.Unmarshal(raw, &config); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		if err := config.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[config.Name] {
+			return nil, fmt.Errorf("queue %s is defined twice", config.Name)
+		}
+		seen[config.Name] = true
+		queues = append(queues, config)
+	}
+	return queues, nil
+}
@@ -0,0 +1,53 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultTickInterval is how often a queue's pool is matched when the file
+// doesn't say otherwise.
+const DefaultTickInterval = time.Second
+
+// Tick schedules the matchmaking passes of a queue. Passes run every Interval
+// whether or not tickets arrive, so waiting tickets keep widening.
+type Tick struct {
+	Interval time.Duration `json:"interval"`
+	// Threshold runs a pass before the interval is up once this many tickets
+	// joined the pool since the previous pass. Zero means MaxPlayers.
+	Threshold int `json:"threshold"`
+}
+
+// UnmarshalJSON accepts the interval as a duration string such as "500ms".
+func (t *Tick) UnmarshalJSON(data []byte) error {
+	type tick Tick
+	aux := struct {
+		*tick
+		Interval string `json:"interval"`
+	}{
+		tick: (*tick)(t),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.Interval != "" {
+		interval, err := time.ParseDuration(aux.Interval)
+		if err != nil {
+			return err
+		}
+		t.Interval = interval
+	}
+	return nil
+}
+
+func (t Tick) Validate() error {
+	if t.Interval <= 0 {
+		return fmt.Errorf("tick interval must be positive")
+	}
+	if t.Threshold < 0 {
+		return fmt.Errorf("tick threshold must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	WebhookJSON     = "json"
+	WebhookProtobuf = "protobuf"
+
+	DefaultWebhookAttempts = 5
+	DefaultWebhookBackoff  = time.Second
+	DefaultWebhookTimeout  = 10 * time.Second
+)
+
+// Webhook pushes every match formed in the queue to a game backend over HTTP.
+type Webhook struct {
+	URL string `json:"url"`
+	// Format is the body encoding: WebhookJSON (default) or WebhookProtobuf.
+	Format string `json:"format"`
+	// Secret signs each body with HMAC-SHA256. SecretEnv names an environment
+	// variable to read it from instead, keeping it out of the config file.
+	Secret    string `json:"secret"`
+	SecretEnv string `json:"secret_env"`
+
+	// MaxAttempts bounds the deliveries of one match; retries wait Backoff,
+	// doubling each time up to MaxBackoff.
+	MaxAttempts int           `json:"max_attempts"`
+	Backoff     time.Duration `json:"backoff"`
+	MaxBackoff  time.Duration `json:"max_backoff"`
+	Timeout     time.Duration `json:"timeout"`
+}
+
+// UnmarshalJSON fills in the defaults and accepts durations as strings such
+// as "500ms".
+func (w *Webhook) UnmarshalJSON(data []byte) error {
+	type webhook Webhook
+	aux := struct {
+		*webhook
+		Backoff    string `json:"backoff"`
+		MaxBackoff string `json:"max_backoff"`
+		Timeout    string `json:"timeout"`
+	}{
+		webhook: (*webhook)(w),
+	}
+	w.Format = WebhookJSON
+	w.MaxAttempts = DefaultWebhookAttempts
+	w.Backoff = DefaultWebhookBackoff
+	w.Timeout = DefaultWebhookTimeout
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	for _, field := range []struct {
+		value string
+		dest  *time.Duration
+	}{
+		{aux.Backoff, &w.Backoff},
+		{aux.MaxBackoff, &w.MaxBackoff},
+		{aux.Timeout, &w.Timeout},
+	} {
+		if field.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(field.value)
+		if err != nil {
+			return err
+		}
+		*field.dest = d
+	}
+
+	if w.SecretEnv != "" {
+		w.Secret = os.Getenv(w.SecretEnv)
+	}
+	return nil
+}
+
+func (w *Webhook) Validate() error {
+	if w.URL == "" {
+		return fmt.Errorf("webhook url is required")
+	}
+	if w.Format != WebhookJSON && w.Format != WebhookProtobuf {
+		return fmt.Errorf("webhook format must be %q or %q", WebhookJSON, WebhookProtobuf)
+	}
+	if w.MaxAttempts < 1 {
+		return fmt.Errorf("webhook max_attempts must be at least 1")
+	}
+	if w.Backoff <= 0 {
+		return fmt.Errorf("webhook backoff must be positive")
+	}
+	if w.Timeout <= 0 {
+		return fmt.Errorf("webhook timeout must be positive")
+	}
+	if w.SecretEnv != "" && w.Secret == "" {
+		return fmt.Errorf("webhook secret_env %s is not set", w.SecretEnv)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package queue
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWebhookValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{name: "defaults", json: `{"url": "http://backend/matches"}`},
+		{name: "protobuf", json: `{"url": "http://backend/matches", "format": "protobuf", "backoff": "500ms", "max_backoff": "5s", "timeout": "2s"}`},
+		{name: "missing url", json: `{}`, want: "url is required"},
+		{name: "unknown format", json: `{"url": "http://backend/matches", "format": "xml"}`, want: "format must be"},
+		{name: "no attempts", json: `{"url": "http://backend/matches", "max_attempts": 0}`, want: "max_attempts"},
+		{name: "zero backoff", json: `{"url": "http://backend/matches", "backoff": "0s"}`, want: "backoff must be positive"},
+		{name: "negative backoff", json: `{"url": "http://backend/matches", "backoff": "-1s"}`, want: "backoff must be positive"},
+		{name: "zero timeout", json: `{"url": "http://backend/matches", "timeout": "0s"}`, want: "timeout must be positive"},
+		{name: "negative timeout", json: `{"url": "http://backend/matches", "timeout": "-5s"}`, want: "timeout must be positive"},
+		{name: "unset secret env", json: `{"url": "http://backend/matches", "secret_env": "MATCHMAKER_TEST_UNSET_SECRET"}`, want: "is not set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w Webhook
+			if err := json.Unmarshal([]byte(tt.json), &w); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+
+			err := w.Validate()
+			if tt.want == "" && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+			if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
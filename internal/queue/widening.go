@@ -0,0 +1,75 @@
+package queue
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	// WidenNone keeps the base windows no matter how long a ticket waits.
+	WidenNone = "none"
+	// WidenStep adds one step per full interval waited.
+	WidenStep = "step"
+	// WidenLinear grows the windows continuously, one step per interval.
+	WidenLinear = "linear"
+)
+
+// Widening describes how a ticket's skill and ping windows grow with the time
+// it has spent in the pool.
+type Widening struct {
+	Schedule string        `json:"schedule"`
+	Interval time.Duration `json:"interval"`
+
+	RatingStep  int `json:"rating_step"`
+	LatencyStep int `json:"latency_step"`
+
+	// Caps for the widened windows; zero or less means no cap.
+	MaxRatingSpread int `json:"max_rating_spread"`
+	MaxLatency      int `json:"max_latency_ms"`
+}
+
+// UnmarshalJSON accepts the interval as a duration string such as "15s".
+func (w *Widening) UnmarshalJSON(data []byte) error {
+	type widening Widening
+	aux := struct {
+		*widening
+		Interval string `json:"interval"`
+	}{
+		widening: (*widening)(w),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if aux.Interval != "" {
+		interval, err := time.ParseDuration(aux.Interval)
+		if err != nil {
+			return err
+		}
+		w.Interval = interval
+	}
+	return nil
+}
+
+// Expand widens base by step according to the schedule after waiting for
+// waited, without going past limit.
+func (w Widening) Expand(base, step, limit int, waited time.Duration) int {
+	if base <= 0 || step <= 0 || w.Interval <= 0 || waited <= 0 {
+		return base
+	}
+
+	var widened int
+	switch w.Schedule {
+	case WidenStep:
+		widened = base + int(waited/w.Interval)*step
+	case WidenLinear:
+		widened = base + int(float64(step)*waited.Seconds()/w.Interval.Seconds())
+	default:
+		return base
+	}
+
+	if limit > 0 && widened > limit {
+		return max(limit, base)
+	}
+	return widened
+}
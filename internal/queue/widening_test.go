@@ -0,0 +1,42 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWideningExpand(t *testing.T) {
+	step := Widening{Schedule: WidenStep, Interval: 15 * time.Second}
+	linear := Widening{Schedule: WidenLinear, Interval: 15 * time.Second}
+
+	tests := []struct {
+		name     string
+		widening Widening
+		base     int
+		step     int
+		limit    int
+		waited   time.Duration
+		want     int
+	}{
+		{"step fresh", step, 200, 50, 1000, 0, 200},
+		{"step before the first interval", step, 200, 50, 1000, 14 * time.Second, 200},
+		{"step after one interval", step, 200, 50, 1000, 15 * time.Second, 250},
+		{"step after two intervals", step, 200, 50, 1000, 44 * time.Second, 300},
+		{"step capped", step, 200, 50, 1000, time.Hour, 1000},
+		{"step uncapped", step, 200, 50, 0, time.Hour, 200 + 240*50},
+		{"linear half an interval", linear, 200, 50, 1000, 7500 * time.Millisecond, 225},
+		{"linear capped", linear, 200, 50, 1000, time.Hour, 1000},
+		{"limit below base keeps base", step, 200, 50, 100, time.Hour, 200},
+		{"unbounded base stays unbounded", step, 0, 50, 1000, time.Hour, 0},
+		{"no step", step, 200, 0, 1000, time.Hour, 200},
+		{"no interval", Widening{Schedule: WidenStep}, 200, 50, 1000, time.Hour, 200},
+		{"none", Widening{Schedule: WidenNone, Interval: 15 * time.Second}, 200, 50, 1000, time.Hour, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.widening.Expand(tt.base, tt.step, tt.limit, tt.waited); got != tt.want {
+				t.Errorf("Expand(%d, %d, %d, %s) = %d, want %d", tt.base, tt.step, tt.limit, tt.waited, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"matchmaker-nats/internal/entities"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const deliveryKeyPrefix = "webhook_delivery:"
+
+var ErrDeliveryNotFound = errors.New("delivery not found")
+
+// RedisDeliveryStore keeps the webhook delivery state of each match for as
+// long as the match itself.
+type RedisDeliveryStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisDeliveryStore(redisClient *redis.Client) *RedisDeliveryStore {
+	return &RedisDeliveryStore{
+		redisClient: redisClient,
+	}
+}
+
+func DeliveryKey(matchID string) string {
+	return deliveryKeyPrefix + matchID
+}
+
+// Save records the delivery and counts finished ones in the queue's StatsKey.
+func (s *RedisDeliveryStore) Save(ctx context.Context, queueName string, delivery *entities.Delivery) error {
+	_, err := s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, DeliveryKey(delivery.MatchID), delivery.ToHash())
+		pipe.Expire(ctx, DeliveryKey(delivery.MatchID), MatchRetention)
+		switch delivery.Status {
+		case entities.DeliveryDelivered:
+			pipe.HIncrBy(ctx, StatsKey(queueName), "webhooks_delivered", 1)
+		case entities.DeliveryFailed:
+			pipe.HIncrBy(ctx, StatsKey(queueName), "webhooks_failed", 1)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisDeliveryStore) Get(ctx context.Context, matchID string) (*entities.Delivery, error) {
+	hash, err := s.redisClient.HGetAll(ctx, DeliveryKey(matchID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(hash) == 0 {
+		return nil, ErrDeliveryNotFound
+	}
+
+	var delivery entities.Delivery
+	if err := delivery.FromHash(hash); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	leaseKeyPrefix = "matchmaker:lease:"
+	replicasKey    = "matchmaker:replicas"
+)
+
+// acquireLeaseScript sets KEYS[1] to the owner in ARGV[1] for ARGV[2]
+// milliseconds when it is free or already held by that owner. It returns 1
+// when the owner holds the lease afterwards.
+var acquireLeaseScript = redis.NewScript(`
+local holder = redis.call("GET", KEYS[1])
+if holder and holder ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`)
+
+// releaseLeaseScript deletes KEYS[1] only while ARGV[1] holds it.
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLeaseStore keeps each lease in a key expiring with it, and the live
+// replicas in a sorted set scored by the time their heartbeat runs out.
+type RedisLeaseStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisLeaseStore(redisClient *redis.Client) *RedisLeaseStore {
+	return &RedisLeaseStore{
+		redisClient: redisClient,
+	}
+}
+
+func LeaseKey(name string) string {
+	return leaseKeyPrefix + name
+}
+
+func (s *RedisLeaseStore) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	held, err := acquireLeaseScript.Run(ctx, s.redisClient, []string{LeaseKey(name)}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return held == 1, nil
+}
+
+func (s *RedisLeaseStore) Release(ctx context.Context, name, owner string) error {
+	return releaseLeaseScript.Run(ctx, s.redisClient, []string{LeaseKey(name)}, owner).Err()
+}
+
+// Heartbeat also drops the replicas whose heartbeat ran out.
+func (s *RedisLeaseStore) Heartbeat(ctx context.Context, replica string, ttl time.Duration) error {
+	now := time.Now()
+	_, err := s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, replicasKey, "-inf", strconv.FormatInt(now.UnixMilli(), 10))
+		pipe.ZAdd(ctx, replicasKey, &redis.Z{
+			Score:  float64(now.Add(ttl).UnixMilli()),
+			Member: replica,
+		})
+		return nil
+	})
+	return err
+}
+
+func (s *RedisLeaseStore) Leave(ctx context.Context, replica string) error {
+	return s.redisClient.ZRem(ctx, replicasKey, replica).Err()
+}
+
+func (s *RedisLeaseStore) Replicas(ctx context.Context) ([]string, error) {
+	return s.redisClient.ZRangeByScore(ctx, replicasKey, &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(time.Now().UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	matchKeyPrefix         = "match:"
+	playerMatchesKeyPrefix = "player_matches:"
+
+	// MatchRetention is how long a match and a player's history are kept
+	// after the last write.
+	MatchRetention = 7 * 24 * time.Hour
+	// PlayerHistorySize caps how many matches are indexed per player.
+	PlayerHistorySize = 100
+)
+
+var ErrMatchNotFound = errors.New("match not found")
+
+// RedisMatchStore keeps one Redis hash per formed match plus a per-player
+// index. The index is a sorted set with equal scores ordered by member, which
+// works because match IDs sort by creation time.
+type RedisMatchStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisMatchStore(redisClient *redis.Client) *RedisMatchStore {
+	return &RedisMatchStore{
+		redisClient: redisClient,
+	}
+}
+
+func MatchKey(matchID string) string {
+	return matchKeyPrefix + matchID
+}
+
+func PlayerMatchesKey(playerID string) string {
+	return playerMatchesKeyPrefix + playerID
+}
+
+func (s *RedisMatchStore) Save(ctx context.Context, match *entities.Match) error {
+	hash, err := match.ToHash()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, MatchKey(match.MatchID), hash)
+		pipe.Expire(ctx, MatchKey(match.MatchID), MatchRetention)
+
+		for _, player := range match.Players {
+			key := PlayerMatchesKey(player.ID)
+			pipe.ZAdd(ctx, key, &redis.Z{Score: 0, Member: match.MatchID})
+			pipe.ZRemRangeByRank(ctx, key, 0, -PlayerHistorySize-1)
+			pipe.Expire(ctx, key, MatchRetention)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisMatchStore) Get(ctx context.Context, matchID string) (*entities.Match, error) {
+	hash, err := s.redisClient.HGetAll(ctx, MatchKey(matchID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(hash) == 0 {
+		return nil, ErrMatchNotFound
+	}
+
+	var match entities.Match
+	if err := match.FromHash(hash); err != nil {
+		return nil, err
+	}
+	return &match, nil
+}
+
+// ListByPlayer returns up to limit of the player's matches, newest first,
+// starting after cursor (a match ID from a previous page; empty for the
+// first page). The returned cursor is empty when there are no more pages.
+func (s *RedisMatchStore) ListByPlayer(ctx context.Context, playerID string, limit int, cursor string) ([]entities.Match, string, error) {
+	upper := "+"
+	if cursor != "" {
+		upper = "(" + cursor
+	}
+
+	matchIDs, err := s.redisClient.ZRevRangeByLex(ctx, PlayerMatchesKey(playerID), &redis.ZRangeBy{
+		Max:   upper,
+		Min:   "-",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, "", err
+	}
+
+	matches := make([]entities.Match, 0, len(matchIDs))
+	for _, matchID := range matchIDs {
+		match, err := s.Get(ctx, matchID)
+		if errors.Is(err, ErrMatchNotFound) {
+			continue // expired before the index caught up
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		matches = append(matches, *match)
+	}
+
+	next := ""
+	if len(matchIDs) == limit {
+		next = matchIDs[len(matchIDs)-1]
+	}
+	return matches, next, nil
+}
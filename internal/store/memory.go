@@ -0,0 +1,508 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+)
+
+// memorySweepInterval is how often MemoryPoolStore drops tickets that left
+// the queue more than TicketRetention ago.
+const memorySweepInterval = time.Minute
+
+// MemoryPoolStore is a PoolStore living in this process's memory. Pools are
+// slices kept in (score, ticket ID) order, like a Redis sorted set.
+type MemoryPoolStore struct {
+	memoryStats
+
+	mu        sync.Mutex
+	tickets   map[string]*entities.Ticket
+	pools     map[string][]PoolEntry
+	claimed   map[string]map[string]memoryClaim
+	players   map[string]string
+	requests  map[string]string
+	lastSweep time.Time
+}
+
+func NewMemoryPoolStore() *MemoryPoolStore {
+	return &MemoryPoolStore{
+		tickets:   make(map[string]*entities.Ticket),
+		pools:     make(map[string][]PoolEntry),
+		claimed:   make(map[string]map[string]memoryClaim),
+		players:   make(map[string]string),
+		requests:  make(map[string]string),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryPoolStore) Enqueue(ctx context.Context, ticket *entities.Ticket, requestKey string) (string, EnqueueResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep()
+
+	idempotencyKey := ""
+	if requestKey != "" {
+		idempotencyKey = IdempotencyKey(ticket.Player.ID, requestKey)
+		if existing, ok := s.requests[idempotencyKey]; ok {
+			return existing, Replayed, nil
+		}
+	}
+	for _, player := range ticket.Players() {
+		if existing, ok := s.players[player.ID]; ok {
+			return existing, AlreadyQueued, nil
+		}
+	}
+
+	if idempotencyKey != "" {
+		s.requests[idempotencyKey] = ticket.ID
+	}
+	for _, player := range ticket.Players() {
+		s.players[player.ID] = ticket.ID
+	}
+	s.tickets[ticket.ID] = cloneTicket(ticket)
+	s.insert(ticket.Queue, PoolEntry{TicketID: ticket.ID, Score: float64(ticket.CreatedAt.Unix())})
+
+	return ticket.ID, Enqueued, nil
+}
+
+func (s *MemoryPoolStore) Get(ctx context.Context, ticketID string) (*entities.Ticket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ticket, ok := s.tickets[ticketID]
+	if !ok {
+		return nil, ErrTicketNotFound
+	}
+	return cloneTicket(ticket), nil
+}
+
+func (s *MemoryPoolStore) Position(ctx context.Context, ticket *entities.Ticket) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, entry := range s.pools[ticket.Queue] {
+		if entry.TicketID == ticket.ID {
+			return int64(i) + 1, nil
+		}
+	}
+	return 0, ErrTicketNotFound
+}
+
+func (s *MemoryPoolStore) Size(ctx context.Context, queueName string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.pools[queueName])), nil
+}
+
+func (s *MemoryPoolStore) Dequeue(ctx context.Context, ticket *entities.Ticket) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pool := s.pools[ticket.Queue]
+	for i, entry := range pool {
+		if entry.TicketID == ticket.ID {
+			s.pools[ticket.Queue] = append(pool[:i], pool[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *MemoryPoolStore) ClaimBatch(ctx context.Context, queueName string, size int) ([]PoolEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pool := s.pools[queueName]
+
+	n := size
+	if n > len(pool) {
+		n = len(pool)
+	}
+	claimed := make([]PoolEntry, n)
+	copy(claimed, pool[:n])
+	s.pools[queueName] = append(pool[:0:0], pool[n:]...)
+
+	if s.claimed[queueName] == nil {
+		s.claimed[queueName] = make(map[string]memoryClaim)
+	}
+	now := time.Now()
+	for _, entry := range claimed {
+		s.claimed[queueName][entry.TicketID] = memoryClaim{score: entry.Score, at: now}
+	}
+	return claimed, nil
+}
+
+func (s *MemoryPoolStore) Requeue(ctx context.Context, queueName string, entries []PoolEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range entries {
+		delete(s.claimed[queueName], entry.TicketID)
+		s.insert(queueName, entry)
+	}
+	s.incr(queueName, "leftover_batches", 1)
+	s.incr(queueName, "leftover_tickets", int64(len(entries)))
+	return nil
+}
+
+func (s *MemoryPoolStore) Release(ctx context.Context, queueName string, ticketIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ticketIDs {
+		delete(s.claimed[queueName], id)
+	}
+	return nil
+}
+
+func (s *MemoryPoolStore) RecoverClaims(ctx context.Context, queueName string, claimedBefore time.Time) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var recovered []string
+	for id, claim := range s.claimed[queueName] {
+		if claim.at.After(claimedBefore) {
+			continue
+		}
+		delete(s.claimed[queueName], id)
+		s.insert(queueName, PoolEntry{TicketID: id, Score: claim.score})
+		recovered = append(recovered, id)
+	}
+	sort.Strings(recovered)
+	return recovered, nil
+}
+
+func (s *MemoryPoolStore) Stale(ctx context.Context, queueName string, before time.Time) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := float64(before.Unix())
+	var stale []string
+	for _, entry := range s.pools[queueName] {
+		if entry.Score > cutoff {
+			break
+		}
+		stale = append(stale, entry.TicketID)
+	}
+	return stale, nil
+}
+
+func (s *MemoryPoolStore) SetStatus(ctx context.Context, ticket *entities.Ticket, status entities.TicketStatus, matchID string) error {
+	return s.setStatus([]*entities.Ticket{ticket}, status, matchID)
+}
+
+func (s *MemoryPoolStore) SetMatched(ctx context.Context, tickets []*entities.Ticket, matchID string) error {
+	return s.setStatus(tickets, entities.TicketMatched, matchID)
+}
+
+func (s *MemoryPoolStore) setStatus(tickets []*entities.Ticket, status entities.TicketStatus, matchID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ticket := range tickets {
+		stored, ok := s.tickets[ticket.ID]
+		if !ok || stored.Status != entities.TicketQueued {
+			return ErrTicketNotQueued
+		}
+	}
+
+	now := time.Now()
+	for _, ticket := range tickets {
+		ticket.Status = status
+		ticket.MatchID = matchID
+		ticket.UpdatedAt = now
+
+		stored := s.tickets[ticket.ID]
+		stored.Status = ticket.Status
+		stored.MatchID = ticket.MatchID
+		stored.UpdatedAt = ticket.UpdatedAt
+		s.release(ticket.ID)
+	}
+	return nil
+}
+
+type memoryClaim struct {
+	score float64
+	at    time.Time
+}
+
+// insert adds the entry to the pool, or moves it if it is already there.
+func (s *MemoryPoolStore) insert(queueName string, entry PoolEntry) {
+	pool := s.pools[queueName]
+	for i, existing := range pool {
+		if existing.TicketID == entry.TicketID {
+			pool = append(pool[:i], pool[i+1:]...)
+			break
+		}
+	}
+
+	i := sort.Search(len(pool), func(i int) bool {
+		if pool[i].Score != entry.Score {
+			return pool[i].Score > entry.Score
+		}
+		return pool[i].TicketID > entry.TicketID
+	})
+	pool = append(pool, PoolEntry{})
+	copy(pool[i+1:], pool[i:])
+	pool[i] = entry
+	s.pools[queueName] = pool
+}
+
+// release frees the players queued with the ticket.
+func (s *MemoryPoolStore) release(ticketID string) {
+	for playerID, id := range s.players {
+		if id == ticketID {
+			delete(s.players, playerID)
+		}
+	}
+}
+
+// sweep forgets tickets, and the request keys pointing at them, that left
+// the queue more than TicketRetention ago.
+func (s *MemoryPoolStore) sweep() {
+	now := time.Now()
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for id, ticket := range s.tickets {
+		if ticket.Status.Terminal() && now.Sub(ticket.UpdatedAt) > TicketRetention {
+			delete(s.tickets, id)
+		}
+	}
+	for key, id := range s.requests {
+		if _, ok := s.tickets[id]; !ok {
+			delete(s.requests, key)
+		}
+	}
+}
+
+func cloneTicket(ticket *entities.Ticket) *entities.Ticket {
+	clone := *ticket
+	clone.Members = append([]entities.Player(nil), ticket.Members...)
+	return &clone
+}
+
+// MemoryMatchStore is a MatchStore living in this process's memory. Matches
+// are kept for as long as the process runs; histories are capped at
+// PlayerHistorySize like in Redis.
+type MemoryMatchStore struct {
+	mu      sync.Mutex
+	matches map[string]entities.Match
+	players map[string][]string
+}
+
+func NewMemoryMatchStore() *MemoryMatchStore {
+	return &MemoryMatchStore{
+		matches: make(map[string]entities.Match),
+		players: make(map[string][]string),
+	}
+}
+
+func (s *MemoryMatchStore) Save(ctx context.Context, match *entities.Match) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.matches[match.MatchID] = *match
+	for _, player := range match.Players {
+		// Match IDs sort by creation time, so the history stays ordered.
+		history := s.players[player.ID]
+		i := sort.SearchStrings(history, match.MatchID)
+		if i < len(history) && history[i] == match.MatchID {
+			continue
+		}
+		history = append(history, "")
+		copy(history[i+1:], history[i:])
+		history[i] = match.MatchID
+		if len(history) > PlayerHistorySize {
+			history = history[len(history)-PlayerHistorySize:]
+		}
+		s.players[player.ID] = history
+	}
+	return nil
+}
+
+func (s *MemoryMatchStore) Get(ctx context.Context, matchID string) (*entities.Match, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	match, ok := s.matches[matchID]
+	if !ok {
+		return nil, ErrMatchNotFound
+	}
+	return &match, nil
+}
+
+func (s *MemoryMatchStore) ListByPlayer(ctx context.Context, playerID string, limit int, cursor string) ([]entities.Match, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.players[playerID]
+	end := len(history)
+	if cursor != "" {
+		end = sort.SearchStrings(history, cursor)
+	}
+
+	var matches []entities.Match
+	for i := end - 1; i >= 0 && len(matches) < limit; i-- {
+		matches = append(matches, s.matches[history[i]])
+	}
+
+	next := ""
+	if len(matches) == limit {
+		next = matches[len(matches)-1].MatchID
+	}
+	return matches, next, nil
+}
+
+// MemoryDeliveryStore is a DeliveryStore living in this process's memory.
+type MemoryDeliveryStore struct {
+	memoryStats
+
+	mu         sync.Mutex
+	deliveries map[string]entities.Delivery
+}
+
+func NewMemoryDeliveryStore() *MemoryDeliveryStore {
+	return &MemoryDeliveryStore{
+		deliveries: make(map[string]entities.Delivery),
+	}
+}
+
+func (s *MemoryDeliveryStore) Save(ctx context.Context, queueName string, delivery *entities.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deliveries[delivery.MatchID] = *delivery
+	switch delivery.Status {
+	case entities.DeliveryDelivered:
+		s.incr(queueName, "webhooks_delivered", 1)
+	case entities.DeliveryFailed:
+		s.incr(queueName, "webhooks_failed", 1)
+	}
+	return nil
+}
+
+func (s *MemoryDeliveryStore) Get(ctx context.Context, matchID string) (*entities.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivery, ok := s.deliveries[matchID]
+	if !ok {
+		return nil, ErrDeliveryNotFound
+	}
+	return &delivery, nil
+}
+
+// MemoryLeaseStore is a LeaseStore living in this process's memory, where
+// the only replica is this one.
+type MemoryLeaseStore struct {
+	mu       sync.Mutex
+	leases   map[string]memoryLease
+	replicas map[string]time.Time
+}
+
+type memoryLease struct {
+	owner   string
+	expires time.Time
+}
+
+func NewMemoryLeaseStore() *MemoryLeaseStore {
+	return &MemoryLeaseStore{
+		leases:   make(map[string]memoryLease),
+		replicas: make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryLeaseStore) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if lease, ok := s.leases[name]; ok && lease.owner != owner && now.Before(lease.expires) {
+		return false, nil
+	}
+	s.leases[name] = memoryLease{owner: owner, expires: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *MemoryLeaseStore) Release(ctx context.Context, name, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.leases[name].owner == owner {
+		delete(s.leases, name)
+	}
+	return nil
+}
+
+func (s *MemoryLeaseStore) Heartbeat(ctx context.Context, replica string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.replicas[replica] = time.Now().Add(ttl)
+	return nil
+}
+
+func (s *MemoryLeaseStore) Leave(ctx context.Context, replica string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.replicas, replica)
+	return nil
+}
+
+func (s *MemoryLeaseStore) Replicas(ctx context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	replicas := make([]string, 0, len(s.replicas))
+	for replica, expires := range s.replicas {
+		if now.Before(expires) {
+			replicas = append(replicas, replica)
+		} else {
+			delete(s.replicas, replica)
+		}
+	}
+	sort.Strings(replicas)
+	return replicas, nil
+}
+
+// memoryStats keeps the counters the Redis stores add to StatsKey.
+type memoryStats struct {
+	mu       sync.Mutex
+	counters map[string]map[string]int64
+}
+
+func (s *memoryStats) incr(queueName, field string, n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.counters == nil {
+		s.counters = make(map[string]map[string]int64)
+	}
+	if s.counters[queueName] == nil {
+		s.counters[queueName] = make(map[string]int64)
+	}
+	s.counters[queueName][field] += n
+}
+
+// Stats returns a copy of the queue's counters, keyed like the StatsKey
+// hash fields.
+func (s *memoryStats) Stats(queueName string) map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make(map[string]int64, len(s.counters[queueName]))
+	for field, n := range s.counters[queueName] {
+		stats[field] = n
+	}
+	return stats
+}
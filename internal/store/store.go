@@ -0,0 +1,129 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// PoolEntry is a ticket waiting in a queue's pool. Score is its enqueue unix
+// time; pools hand out the lowest scores first.
+type PoolEntry struct {
+	TicketID string
+	Score    float64
+}
+
+// EnqueueResult tells what Enqueue did with a ticket.
+type EnqueueResult string
+
+const (
+	// Enqueued means the ticket was added to the pool.
+	Enqueued EnqueueResult = "enqueued"
+	// AlreadyQueued means a player on the ticket is queued with another one.
+	AlreadyQueued EnqueueResult = "queued"
+	// Replayed means the idempotency key already created a ticket; nothing
+	// was changed.
+	Replayed EnqueueResult = "replayed"
+)
+
+// PoolStore holds the tickets and, per queue, the FIFO pool of the ones
+// still waiting for a match.
+type PoolStore interface {
+	// Enqueue stores the ticket and adds it to its queue's pool, unless
+	// requestKey was already used by the ticket's player or a player on it
+	// is already queued; the existing ticket's ID is returned then.
+	Enqueue(ctx context.Context, ticket *entities.Ticket, requestKey string) (string, EnqueueResult, error)
+	// Get returns ErrTicketNotFound for unknown tickets.
+	Get(ctx context.Context, ticketID string) (*entities.Ticket, error)
+	// Position is the 1-based place of a queued ticket in its pool.
+	Position(ctx context.Context, ticket *entities.Ticket) (int64, error)
+	Size(ctx context.Context, queueName string) (int64, error)
+	// Dequeue removes the ticket from its pool and reports whether it was
+	// still there.
+	Dequeue(ctx context.Context, ticket *entities.Ticket) (bool, error)
+	// ClaimBatch atomically removes up to size of the oldest entries from
+	// the pool. They stay claimed until Requeue or Release; RecoverClaims
+	// returns the ones a crashed worker never finished.
+	ClaimBatch(ctx context.Context, queueName string, size int) ([]PoolEntry, error)
+	// Requeue returns claimed entries to the pool with their scores.
+	Requeue(ctx context.Context, queueName string, entries []PoolEntry) error
+	// Release ends the claims of tickets that left the pool for good.
+	Release(ctx context.Context, queueName string, ticketIDs []string) error
+	// RecoverClaims requeues the entries claimed before the given time and
+	// returns their ticket IDs.
+	RecoverClaims(ctx context.Context, queueName string, claimedBefore time.Time) ([]string, error)
+	// Stale lists the tickets queued before the given time.
+	Stale(ctx context.Context, queueName string, before time.Time) ([]string, error)
+	// SetStatus moves a queued ticket to a final state and releases its
+	// players so they can queue again. A ticket leaves the queue once: if it
+	// already did, nothing changes and ErrTicketNotQueued is returned.
+	SetStatus(ctx context.Context, ticket *entities.Ticket, status entities.TicketStatus, matchID string) error
+	// SetMatched is SetStatus to matched for all tickets of a match at once:
+	// either all of them are still queued and change, or none does.
+	SetMatched(ctx context.Context, tickets []*entities.Ticket, matchID string) error
+}
+
+// MatchStore keeps formed matches and each player's match history.
+type MatchStore interface {
+	Save(ctx context.Context, match *entities.Match) error
+	// Get returns ErrMatchNotFound for unknown matches.
+	Get(ctx context.Context, matchID string) (*entities.Match, error)
+	// ListByPlayer pages through the player's matches, newest first; see
+	// RedisMatchStore.ListByPlayer.
+	ListByPlayer(ctx context.Context, playerID string, limit int, cursor string) ([]entities.Match, string, error)
+}
+
+// DeliveryStore keeps the webhook delivery state of matches.
+type DeliveryStore interface {
+	Save(ctx context.Context, queueName string, delivery *entities.Delivery) error
+	// Get returns ErrDeliveryNotFound when nothing was delivered for the match.
+	Get(ctx context.Context, matchID string) (*entities.Delivery, error)
+}
+
+// LeaseStore hands out expiring leases, so exactly one replica at a time owns
+// a queue's matching loop, and tracks which replicas are alive to share the
+// queues out between them.
+type LeaseStore interface {
+	// Acquire takes the lease for owner, or extends it when owner already
+	// holds it, and reports whether owner holds it now.
+	Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	// Release gives the lease up if owner holds it.
+	Release(ctx context.Context, name, owner string) error
+	// Heartbeat marks the replica alive for ttl.
+	Heartbeat(ctx context.Context, replica string, ttl time.Duration) error
+	// Leave drops the replica before its heartbeat runs out.
+	Leave(ctx context.Context, replica string) error
+	// Replicas lists the replicas whose heartbeat has not run out.
+	Replicas(ctx context.Context) ([]string, error)
+}
+
+// Stores bundles the storage shared by the API and the workers.
+type Stores struct {
+	Pool       PoolStore
+	Matches    MatchStore
+	Deliveries DeliveryStore
+	Leases     LeaseStore
+}
+
+func NewRedisStores(redisClient *redis.Client) Stores {
+	return Stores{
+		Pool:       NewRedisPoolStore(redisClient),
+		Matches:    NewRedisMatchStore(redisClient),
+		Deliveries: NewRedisDeliveryStore(redisClient),
+		Leases:     NewRedisLeaseStore(redisClient),
+	}
+}
+
+// NewMemoryStores keeps everything in this process, for tests and for
+// running API and workers as a single binary.
+func NewMemoryStores() Stores {
+	return Stores{
+		Pool:       NewMemoryPoolStore(),
+		Matches:    NewMemoryMatchStore(),
+		Deliveries: NewMemoryDeliveryStore(),
+		Leases:     NewMemoryLeaseStore(),
+	}
+}
@@ -0,0 +1,394 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+const testQueue = "test"
+
+// poolContract runs the PoolStore contract against both implementations.
+// stats reads the counters the store keeps for a queue.
+func poolContract(t *testing.T, test func(t *testing.T, pool PoolStore, stats func(queueName string) map[string]int64)) {
+	t.Run("memory", func(t *testing.T) {
+		pool := NewMemoryPoolStore()
+		test(t, pool, pool.Stats)
+	})
+	t.Run("redis", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { rdb.Close() })
+
+		test(t, NewRedisPoolStore(rdb), func(queueName string) map[string]int64 {
+			hash, err := rdb.HGetAll(context.Background(), StatsKey(queueName)).Result()
+			if err != nil {
+				t.Fatalf("stats: %v", err)
+			}
+			stats := make(map[string]int64, len(hash))
+			for field, value := range hash {
+				n, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					t.Fatalf("stats field %s: %v", field, err)
+				}
+				stats[field] = n
+			}
+			return stats
+		})
+	})
+}
+
+// newTicket returns a queued ticket for player and party created at the given
+// time; scores are in whole seconds.
+func newTicket(id, player string, created time.Time, party ...string) *entities.Ticket {
+	ticket := &entities.Ticket{
+		ID:        id,
+		Player:    entities.Player{ID: player, MMR: 1500},
+		Queue:     testQueue,
+		Status:    entities.TicketQueued,
+		CreatedAt: created.Truncate(time.Second),
+		UpdatedAt: created.Truncate(time.Second),
+	}
+	for _, member := range party {
+		ticket.Members = append(ticket.Members, entities.Player{ID: member, MMR: 1500})
+	}
+	return ticket
+}
+
+func enqueue(t *testing.T, pool PoolStore, ticket *entities.Ticket, requestKey string) (string, EnqueueResult) {
+	t.Helper()
+
+	id, result, err := pool.Enqueue(context.Background(), ticket, requestKey)
+	if err != nil {
+		t.Fatalf("enqueue %s: %v", ticket.ID, err)
+	}
+	return id, result
+}
+
+// enqueueAll queues one ticket per second, the first one oldest.
+func enqueueAll(t *testing.T, pool PoolStore, n int) []*entities.Ticket {
+	t.Helper()
+
+	start := time.Now().Add(-time.Minute)
+	tickets := make([]*entities.Ticket, n)
+	for i := range tickets {
+		tickets[i] = newTicket(fmt.Sprintf("ticket-%d", i), fmt.Sprintf("player-%d", i), start.Add(time.Duration(i)*time.Second))
+		if _, result := enqueue(t, pool, tickets[i], ""); result != Enqueued {
+			t.Fatalf("enqueue %s = %s, want %s", tickets[i].ID, result, Enqueued)
+		}
+	}
+	return tickets
+}
+
+func size(t *testing.T, pool PoolStore) int64 {
+	t.Helper()
+
+	n, err := pool.Size(context.Background(), testQueue)
+	if err != nil {
+		t.Fatalf("size: %v", err)
+	}
+	return n
+}
+
+func ticketIDs(entries []PoolEntry) []string {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.TicketID
+	}
+	return ids
+}
+
+func TestPoolStoreEnqueue(t *testing.T) {
+	poolContract(t, func(t *testing.T, pool PoolStore, stats func(string) map[string]int64) {
+		ctx := context.Background()
+		ticket := newTicket("ticket-1", "player-1", time.Now(), "player-2")
+
+		if id, result := enqueue(t, pool, ticket, ""); id != ticket.ID || result != Enqueued {
+			t.Fatalf("enqueue = %s, %s; want %s, %s", id, result, ticket.ID, Enqueued)
+		}
+
+		got, err := pool.Get(ctx, ticket.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.Player.ID != "player-1" || len(got.Members) != 1 || got.Members[0].ID != "player-2" || got.Status != entities.TicketQueued || got.Queue != testQueue {
+			t.Errorf("get = %+v, want the enqueued ticket", got)
+		}
+		if position, err := pool.Position(ctx, ticket); err != nil || position != 1 {
+			t.Errorf("position = %d, %v; want 1", position, err)
+		}
+		if n := size(t, pool); n != 1 {
+			t.Errorf("size = %d, want 1", n)
+		}
+
+		// Any player of a queued ticket blocks another one.
+		for _, player := range []string{"player-1", "player-2"} {
+			other := newTicket("ticket-"+player, player, time.Now())
+			if id, result := enqueue(t, pool, other, ""); id != ticket.ID || result != AlreadyQueued {
+				t.Errorf("enqueue %s again = %s, %s; want %s, %s", player, id, result, ticket.ID, AlreadyQueued)
+			}
+		}
+		if n := size(t, pool); n != 1 {
+			t.Errorf("size = %d after rejected enqueues, want 1", n)
+		}
+
+		if _, err := pool.Get(ctx, "missing"); err != ErrTicketNotFound {
+			t.Errorf("get missing ticket = %v, want %v", err, ErrTicketNotFound)
+		}
+	})
+}
+
+func TestPoolStoreIdempotentReplay(t *testing.T) {
+	poolContract(t, func(t *testing.T, pool PoolStore, stats func(string) map[string]int64) {
+		ctx := context.Background()
+		ticket := newTicket("ticket-1", "player-1", time.Now())
+		enqueue(t, pool, ticket, "request-1")
+
+		retry := newTicket("ticket-2", "player-1", time.Now())
+		if id, result := enqueue(t, pool, retry, "request-1"); id != ticket.ID || result != Replayed {
+			t.Errorf("retry = %s, %s; want %s, %s", id, result, ticket.ID, Replayed)
+		}
+		if _, err := pool.Get(ctx, retry.ID); err != ErrTicketNotFound {
+			t.Errorf("retry stored a ticket: %v", err)
+		}
+
+		// The key is replayed even after the ticket left the queue.
+		if err := pool.SetStatus(ctx, ticket, entities.TicketMatched, "match_1"); err != nil {
+			t.Fatalf("set status: %v", err)
+		}
+		if id, result := enqueue(t, pool, retry, "request-1"); id != ticket.ID || result != Replayed {
+			t.Errorf("retry after match = %s, %s; want %s, %s", id, result, ticket.ID, Replayed)
+		}
+
+		// Keys are per player.
+		other := newTicket("ticket-3", "player-2", time.Now())
+		if id, result := enqueue(t, pool, other, "request-1"); id != other.ID || result != Enqueued {
+			t.Errorf("other player = %s, %s; want %s, %s", id, result, other.ID, Enqueued)
+		}
+
+		// Player "a:b" with key "c" and player "a" with key "b:c" differ.
+		enqueue(t, pool, newTicket("ticket-4", "a:b", time.Now()), "c")
+		colliding := newTicket("ticket-5", "a", time.Now())
+		if id, result := enqueue(t, pool, colliding, "b:c"); id != colliding.ID || result != Enqueued {
+			t.Errorf("colliding key = %s, %s; want %s, %s", id, result, colliding.ID, Enqueued)
+		}
+	})
+}
+
+func TestPoolStoreSetStatusReleasesPlayers(t *testing.T) {
+	poolContract(t, func(t *testing.T, pool PoolStore, stats func(string) map[string]int64) {
+		ctx := context.Background()
+		ticket := newTicket("ticket-1", "player-1", time.Now(), "player-2")
+		enqueue(t, pool, ticket, "")
+
+		if err := pool.SetStatus(ctx, ticket, entities.TicketMatched, "match_1"); err != nil {
+			t.Fatalf("set status: %v", err)
+		}
+		got, err := pool.Get(ctx, ticket.ID)
+		if err != nil || got.Status != entities.TicketMatched || got.MatchID != "match_1" {
+			t.Fatalf("get = %+v, %v; want matched in match_1", got, err)
+		}
+
+		again := newTicket("ticket-2", "player-2", time.Now())
+		if id, result := enqueue(t, pool, again, ""); id != again.ID || result != Enqueued {
+			t.Errorf("enqueue after match = %s, %s; want %s, %s", id, result, again.ID, Enqueued)
+		}
+	})
+}
+
+func TestPoolStoreClaimBatch(t *testing.T) {
+	poolContract(t, func(t *testing.T, pool PoolStore, stats func(string) map[string]int64) {
+		ctx := context.Background()
+		tickets := enqueueAll(t, pool, 5)
+
+		claimed, err := pool.ClaimBatch(ctx, testQueue, 3)
+		if err != nil {
+			t.Fatalf("claim: %v", err)
+		}
+		want := []string{"ticket-0", "ticket-1", "ticket-2"}
+		if got := ticketIDs(claimed); !reflect.DeepEqual(got, want) {
+			t.Fatalf("claimed %v, want the oldest %v", got, want)
+		}
+		for i, entry := range claimed {
+			if entry.Score != float64(tickets[i].CreatedAt.Unix()) {
+				t.Errorf("score of %s = %.0f, want %d", entry.TicketID, entry.Score, tickets[i].CreatedAt.Unix())
+			}
+		}
+		if n := size(t, pool); n != 2 {
+			t.Errorf("size = %d after claiming 3 of 5, want 2", n)
+		}
+
+		rest, err := pool.ClaimBatch(ctx, testQueue, 3)
+		if err != nil || len(rest) != 2 {
+			t.Fatalf("claim rest = %v, %v; want 2 tickets", ticketIDs(rest), err)
+		}
+		empty, err := pool.ClaimBatch(ctx, testQueue, 3)
+		if err != nil || len(empty) != 0 {
+			t.Errorf("claim empty pool = %v, %v; want nothing", ticketIDs(empty), err)
+		}
+	})
+}
+
+func TestPoolStoreRequeue(t *testing.T) {
+	poolContract(t, func(t *testing.T, pool PoolStore, stats func(string) map[string]int64) {
+		ctx := context.Background()
+		tickets := enqueueAll(t, pool, 4)
+
+		claimed, err := pool.ClaimBatch(ctx, testQueue, 3)
+		if err != nil {
+			t.Fatalf("claim: %v", err)
+		}
+		if err := pool.Requeue(ctx, testQueue, claimed[1:]); err != nil {
+			t.Fatalf("requeue: %v", err)
+		}
+
+		if n := size(t, pool); n != 3 {
+			t.Errorf("size = %d, want 3", n)
+		}
+		// Requeued tickets keep their place ahead of newer ones.
+		for i, ticket := range tickets[1:] {
+			if position, err := pool.Position(ctx, ticket); err != nil || position != int64(i+1) {
+				t.Errorf("position of %s = %d, %v; want %d", ticket.ID, position, err, i+1)
+			}
+		}
+
+		want := map[string]int64{"leftover_batches": 1, "leftover_tickets": 2}
+		if got := stats(testQueue); !reflect.DeepEqual(got, want) {
+			t.Errorf("stats = %v, want %v", got, want)
+		}
+
+		// Requeued tickets are no longer claimed.
+		recovered, err := pool.RecoverClaims(ctx, testQueue, time.Now().Add(time.Hour))
+		if err != nil || !reflect.DeepEqual(recovered, []string{"ticket-0"}) {
+			t.Errorf("recovered %v, %v; want only the unrequeued ticket-0", recovered, err)
+		}
+	})
+}
+
+func TestPoolStoreRecoverClaims(t *testing.T) {
+	poolContract(t, func(t *testing.T, pool PoolStore, stats func(string) map[string]int64) {
+		ctx := context.Background()
+		tickets := enqueueAll(t, pool, 3)
+
+		if _, err := pool.ClaimBatch(ctx, testQueue, 3); err != nil {
+			t.Fatalf("claim: %v", err)
+		}
+		if err := pool.Release(ctx, testQueue, []string{"ticket-1"}); err != nil {
+			t.Fatalf("release: %v", err)
+		}
+
+		recovered, err := pool.RecoverClaims(ctx, testQueue, time.Now().Add(-time.Minute))
+		if err != nil || len(recovered) != 0 {
+			t.Errorf("recovered fresh claims %v, %v", recovered, err)
+		}
+
+		recovered, err = pool.RecoverClaims(ctx, testQueue, time.Now().Add(time.Second))
+		if err != nil || !reflect.DeepEqual(recovered, []string{"ticket-0", "ticket-2"}) {
+			t.Fatalf("recovered %v, %v; want ticket-0 and ticket-2", recovered, err)
+		}
+		if position, err := pool.Position(ctx, tickets[0]); err != nil || position != 1 {
+			t.Errorf("position of recovered ticket-0 = %d, %v; want 1", position, err)
+		}
+		if n := size(t, pool); n != 2 {
+			t.Errorf("size = %d, want 2", n)
+		}
+	})
+}
+
+func TestPoolStoreStaleAndDequeue(t *testing.T) {
+	poolContract(t, func(t *testing.T, pool PoolStore, stats func(string) map[string]int64) {
+		ctx := context.Background()
+		old := newTicket("ticket-old", "player-1", time.Now().Add(-10*time.Minute))
+		fresh := newTicket("ticket-fresh", "player-2", time.Now())
+		enqueue(t, pool, old, "")
+		enqueue(t, pool, fresh, "")
+
+		stale, err := pool.Stale(ctx, testQueue, time.Now().Add(-5*time.Minute))
+		if err != nil || !reflect.DeepEqual(stale, []string{"ticket-old"}) {
+			t.Fatalf("stale = %v, %v; want [ticket-old]", stale, err)
+		}
+
+		if removed, err := pool.Dequeue(ctx, old); err != nil || !removed {
+			t.Errorf("dequeue = %t, %v; want removed", removed, err)
+		}
+		if removed, err := pool.Dequeue(ctx, old); err != nil || removed {
+			t.Errorf("second dequeue = %t, %v; want not removed", removed, err)
+		}
+		if _, err := pool.Position(ctx, old); err != ErrTicketNotFound {
+			t.Errorf("position of dequeued ticket = %v, want %v", err, ErrTicketNotFound)
+		}
+		if n := size(t, pool); n != 1 {
+			t.Errorf("size = %d, want 1", n)
+		}
+	})
+}
+
+func TestMemoryDeliveryStoreCountsFinishedDeliveries(t *testing.T) {
+	ctx := context.Background()
+	deliveries := NewMemoryDeliveryStore()
+
+	for _, delivery := range []entities.Delivery{
+		{MatchID: "match_1", Status: entities.DeliveryPending},
+		{MatchID: "match_1", Status: entities.DeliveryDelivered},
+		{MatchID: "match_2", Status: entities.DeliveryPending},
+		{MatchID: "match_2", Status: entities.DeliveryFailed},
+	} {
+		if err := deliveries.Save(ctx, testQueue, &delivery); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	want := map[string]int64{"webhooks_delivered": 1, "webhooks_failed": 1}
+	if got := deliveries.Stats(testQueue); !reflect.DeepEqual(got, want) {
+		t.Errorf("stats = %v, want %v", got, want)
+	}
+}
+
+func TestPoolStoreTicketsLeaveTheQueueOnce(t *testing.T) {
+	poolContract(t, func(t *testing.T, pool PoolStore, stats func(string) map[string]int64) {
+		ctx := context.Background()
+		tickets := enqueueAll(t, pool, 3)
+
+		if err := pool.SetStatus(ctx, tickets[0], entities.TicketCancelled, ""); err != nil {
+			t.Fatalf("cancel: %v", err)
+		}
+		if err := pool.SetStatus(ctx, tickets[0], entities.TicketExpired, ""); err != ErrTicketNotQueued {
+			t.Errorf("expire cancelled ticket = %v, want %v", err, ErrTicketNotQueued)
+		}
+
+		// One ticket already left, so none of the match flips.
+		if err := pool.SetMatched(ctx, tickets, "match_1"); err != ErrTicketNotQueued {
+			t.Fatalf("match with cancelled ticket = %v, want %v", err, ErrTicketNotQueued)
+		}
+		for i, want := range []entities.TicketStatus{entities.TicketCancelled, entities.TicketQueued, entities.TicketQueued} {
+			got, err := pool.Get(ctx, tickets[i].ID)
+			if err != nil || got.Status != want {
+				t.Errorf("ticket %s = %+v, %v; want %s", tickets[i].ID, got, err, want)
+			}
+		}
+
+		if err := pool.SetMatched(ctx, tickets[1:], "match_2"); err != nil {
+			t.Fatalf("match: %v", err)
+		}
+		for _, ticket := range tickets[1:] {
+			got, err := pool.Get(ctx, ticket.ID)
+			if err != nil || got.Status != entities.TicketMatched || got.MatchID != "match_2" {
+				t.Errorf("ticket %s = %+v, %v; want matched in match_2", ticket.ID, got, err)
+			}
+			if ticket.Status != entities.TicketMatched || ticket.MatchID != "match_2" {
+				t.Errorf("ticket %s not updated in place: %+v", ticket.ID, ticket)
+			}
+		}
+		if err := pool.SetStatus(ctx, tickets[1], entities.TicketCancelled, ""); err != ErrTicketNotQueued {
+			t.Errorf("cancel matched ticket = %v, want %v", err, ErrTicketNotQueued)
+		}
+	})
+}
@@ -0,0 +1,376 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	poolKeyPrefix         = "player_pool:"
+	claimedKeyPrefix      = "player_pool_claimed:"
+	claimedScoresPrefix   = "player_pool_claimed_scores:"
+	statsKeyPrefix        = "matchmaker:stats:"
+	ticketKeyPrefix       = "ticket:"
+	playerTicketKeyPrefix = "player_ticket:"
+	idempotencyKeyPrefix  = "idempotency:"
+
+	// TicketRetention is how long a ticket hash is kept around so clients can
+	// still look up the outcome after it left the queue.
+	TicketRetention = 24 * time.Hour
+	// ClaimTimeout is how long a claimed ticket may stay out of the pool
+	// before a pass puts it back, in case its worker died holding it.
+	ClaimTimeout = time.Minute
+)
+
+var ErrTicketNotFound = errors.New("ticket not found")
+
+// ErrTicketNotQueued means the ticket already reached a final state, so the
+// status change lost the race against a match, cancellation or expiry.
+var ErrTicketNotQueued = errors.New("ticket is no longer queued")
+
+// reservePlayersScript points the idempotency key in KEYS[1] and every
+// player in the remaining KEYS at the new ticket. If the key was used before
+// it returns {"replayed", ticket}; if a player already has a ticket it
+// returns {"queued", ticket}. An empty KEYS[1] means no key was given.
+var reservePlayersScript = redis.NewScript(`
+if KEYS[1] ~= "" then
+	local existing = redis.call("GET", KEYS[1])
+	if existing then
+		return {"replayed", existing}
+	end
+end
+for i = 2, #KEYS do
+	local existing = redis.call("GET", KEYS[i])
+	if existing then
+		return {"queued", existing}
+	end
+end
+for i, key in ipairs(KEYS) do
+	if key ~= "" then
+		redis.call("SET", key, ARGV[1], "EX", ARGV[2])
+	end
+end
+return {"enqueued", ARGV[1]}
+`)
+
+// releasePlayersScript drops the player -> ticket index entries that still
+// point at the given ticket, so a newer ticket for the same player is kept.
+var releasePlayersScript = redis.NewScript(`
+for _, key in ipairs(KEYS) do
+	if redis.call("GET", key) == ARGV[1] then
+		redis.call("DEL", key)
+	end
+end
+return 0
+`)
+
+// setStatusScript moves every ticket hash in KEYS from the queued status in
+// ARGV[4] to ARGV[1] with match ID ARGV[2] at ARGV[3], or none of them when
+// one already left the queue. It returns 1 when they were moved.
+var setStatusScript = redis.NewScript(`
+for _, key in ipairs(KEYS) do
+	if redis.call("HGET", key, "status") ~= ARGV[4] then
+		return 0
+	end
+end
+for _, key in ipairs(KEYS) do
+	redis.call("HSET", key, "status", ARGV[1], "match_id", ARGV[2], "updated_at", ARGV[3])
+end
+return 1
+`)
+
+// claimBatchScript pops up to ARGV[1] of the oldest tickets of the pool in
+// KEYS[1] and records them as claimed at ARGV[2] in KEYS[2], keeping their
+// scores in the KEYS[3] hash for RecoverClaims.
+var claimBatchScript = redis.NewScript(`
+local popped = redis.call("ZPOPMIN", KEYS[1], ARGV[1])
+for i = 1, #popped, 2 do
+	redis.call("ZADD", KEYS[2], ARGV[2], popped[i])
+	redis.call("HSET", KEYS[3], popped[i], popped[i + 1])
+end
+return popped
+`)
+
+// recoverClaimsScript moves the tickets claimed up to ARGV[1] from KEYS[1]
+// back into the pool in KEYS[3] with the scores kept in KEYS[2].
+var recoverClaimsScript = redis.NewScript(`
+local expired = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1])
+for _, id in ipairs(expired) do
+	local score = redis.call("HGET", KEYS[2], id)
+	if score then
+		redis.call("ZADD", KEYS[3], score, id)
+	end
+	redis.call("ZREM", KEYS[1], id)
+	redis.call("HDEL", KEYS[2], id)
+end
+return expired
+`)
+
+// RedisPoolStore keeps one Redis hash per ticket next to a player_pool sorted
+// set per queue, whose members are ticket IDs scored by enqueue time.
+type RedisPoolStore struct {
+	redisClient *redis.Client
+}
+
+func NewRedisPoolStore(redisClient *redis.Client) *RedisPoolStore {
+	return &RedisPoolStore{
+		redisClient: redisClient,
+	}
+}
+
+// PoolKey is the sorted set holding the tickets of a queue.
+func PoolKey(queueName string) string {
+	return poolKeyPrefix + queueName
+}
+
+// StatsKey holds the matchmaking counters of a queue as hash fields.
+func StatsKey(queueName string) string {
+	return statsKeyPrefix + queueName
+}
+
+// ClaimedKey lists the tickets of a queue taken out by ClaimBatch, scored
+// by claim time in milliseconds.
+func ClaimedKey(queueName string) string {
+	return claimedKeyPrefix + queueName
+}
+
+func claimedScoresKey(queueName string) string {
+	return claimedScoresPrefix + queueName
+}
+
+func TicketKey(ticketID string) string {
+	return ticketKeyPrefix + ticketID
+}
+
+func PlayerTicketKey(playerID string) string {
+	return playerTicketKeyPrefix + playerID
+}
+
+// IdempotencyKey maps a client-supplied request key to the ticket it created.
+// Keys are scoped to the player queueing so clients can't collide; the player
+// ID is length-prefixed since both parts may contain ':'.
+func IdempotencyKey(playerID, requestKey string) string {
+	return idempotencyKeyPrefix + strconv.Itoa(len(playerID)) + ":" + playerID + ":" + requestKey
+}
+
+func playerTicketKeys(ticket *entities.Ticket) []string {
+	players := ticket.Players()
+	keys := make([]string, len(players))
+	for i, player := range players {
+		keys[i] = PlayerTicketKey(player.ID)
+	}
+	return keys
+}
+
+// Enqueue stores the ticket and adds it to the pool, unless requestKey was
+// already used by the ticket's player or a player on it is already queued.
+// In those cases the existing ticket's ID is returned with the reason. An
+// empty requestKey disables the idempotency check.
+func (s *RedisPoolStore) Enqueue(ctx context.Context, ticket *entities.Ticket, requestKey string) (string, EnqueueResult, error) {
+	hash, err := ticket.ToHash()
+	if err != nil {
+		return "", "", err
+	}
+
+	idempotencyKey := ""
+	if requestKey != "" {
+		idempotencyKey = IdempotencyKey(ticket.Player.ID, requestKey)
+	}
+	reservedKeys := append([]string{idempotencyKey}, playerTicketKeys(ticket)...)
+
+	reply, err := reservePlayersScript.Run(ctx, s.redisClient, reservedKeys, ticket.ID, int(TicketRetention.Seconds())).StringSlice()
+	if err != nil {
+		return "", "", err
+	}
+	if len(reply) != 2 {
+		return "", "", fmt.Errorf("unexpected reserve reply %v", reply)
+	}
+	if result := EnqueueResult(reply[0]); result != Enqueued {
+		return reply[1], result, nil
+	}
+
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, TicketKey(ticket.ID), hash)
+		pipe.Expire(ctx, TicketKey(ticket.ID), TicketRetention)
+		pipe.ZAdd(ctx, PoolKey(ticket.Queue), &redis.Z{
+			Score:  float64(ticket.CreatedAt.Unix()),
+			Member: ticket.ID,
+		})
+		return nil
+	})
+	if err != nil {
+		releasePlayersScript.Run(ctx, s.redisClient, reservedKeys, ticket.ID)
+		return "", "", err
+	}
+
+	return ticket.ID, Enqueued, nil
+}
+
+func (s *RedisPoolStore) Get(ctx context.Context, ticketID string) (*entities.Ticket, error) {
+	hash, err := s.redisClient.HGetAll(ctx, TicketKey(ticketID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(hash) == 0 {
+		return nil, ErrTicketNotFound
+	}
+
+	var ticket entities.Ticket
+	if err := ticket.FromHash(hash); err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// Position returns the 1-based place of a queued ticket in its pool.
+func (s *RedisPoolStore) Position(ctx context.Context, ticket *entities.Ticket) (int64, error) {
+	rank, err := s.redisClient.ZRank(ctx, PoolKey(ticket.Queue), ticket.ID).Result()
+	if err == redis.Nil {
+		return 0, ErrTicketNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return rank + 1, nil
+}
+
+// Dequeue removes the ticket from the pool and reports whether it was still
+// there; callers only change the status when it was.
+func (s *RedisPoolStore) Dequeue(ctx context.Context, ticket *entities.Ticket) (bool, error) {
+	removed, err := s.redisClient.ZRem(ctx, PoolKey(ticket.Queue), ticket.ID).Result()
+	if err != nil {
+		return false, err
+	}
+	return removed > 0, nil
+}
+
+// Size returns how many tickets are in the queue's pool.
+func (s *RedisPoolStore) Size(ctx context.Context, queueName string) (int64, error) {
+	return s.redisClient.ZCard(ctx, PoolKey(queueName)).Result()
+}
+
+// ClaimBatch atomically moves up to size of the oldest tickets from the
+// queue's pool to its ClaimedKey and returns them with their enqueue scores,
+// so concurrent workers never see the same ticket. Whether the tickets hold
+// enough players for a match is up to the matcher: parties make tickets and
+// players differ.
+func (s *RedisPoolStore) ClaimBatch(ctx context.Context, queueName string, size int) ([]PoolEntry, error) {
+	keys := []string{PoolKey(queueName), ClaimedKey(queueName), claimedScoresKey(queueName)}
+	reply, err := claimBatchScript.Run(ctx, s.redisClient, keys, size, time.Now().UnixMilli()).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]PoolEntry, 0, len(reply)/2)
+	for i := 0; i+1 < len(reply); i += 2 {
+		score, err := strconv.ParseFloat(reply[i+1], 64)
+		if err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, PoolEntry{TicketID: reply[i], Score: score})
+	}
+	return claimed, nil
+}
+
+// Release ends the claims of tickets that left the pool for good.
+func (s *RedisPoolStore) Release(ctx context.Context, queueName string, ticketIDs []string) error {
+	if len(ticketIDs) == 0 {
+		return nil
+	}
+
+	members := make([]interface{}, len(ticketIDs))
+	for i, id := range ticketIDs {
+		members[i] = id
+	}
+	_, err := s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, ClaimedKey(queueName), members...)
+		pipe.HDel(ctx, claimedScoresKey(queueName), ticketIDs...)
+		return nil
+	})
+	return err
+}
+
+// RecoverClaims returns the tickets claimed before the given time to the
+// pool and lists them.
+func (s *RedisPoolStore) RecoverClaims(ctx context.Context, queueName string, claimedBefore time.Time) ([]string, error) {
+	keys := []string{ClaimedKey(queueName), claimedScoresKey(queueName), PoolKey(queueName)}
+	return recoverClaimsScript.Run(ctx, s.redisClient, keys, claimedBefore.UnixMilli()).StringSlice()
+}
+
+// Requeue puts claimed tickets back into the queue's pool with the given
+// scores, ending their claims, and counts the occurrence in the queue's
+// StatsKey.
+func (s *RedisPoolStore) Requeue(ctx context.Context, queueName string, tickets []PoolEntry) error {
+	members := make([]*redis.Z, len(tickets))
+	claimed := make([]interface{}, len(tickets))
+	ids := make([]string, len(tickets))
+	for i, entry := range tickets {
+		members[i] = &redis.Z{Score: entry.Score, Member: entry.TicketID}
+		claimed[i] = entry.TicketID
+		ids[i] = entry.TicketID
+	}
+
+	_, err := s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, PoolKey(queueName), members...)
+		pipe.ZRem(ctx, ClaimedKey(queueName), claimed...)
+		pipe.HDel(ctx, claimedScoresKey(queueName), ids...)
+		pipe.HIncrBy(ctx, StatsKey(queueName), "leftover_batches", 1)
+		pipe.HIncrBy(ctx, StatsKey(queueName), "leftover_tickets", int64(len(tickets)))
+		return nil
+	})
+	return err
+}
+
+// Stale returns the IDs of the tickets queued before the given time.
+func (s *RedisPoolStore) Stale(ctx context.Context, queueName string, before time.Time) ([]string, error) {
+	return s.redisClient.ZRangeByScore(ctx, PoolKey(queueName), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(before.Unix(), 10),
+	}).Result()
+}
+
+// SetStatus moves a queued ticket to a final state and releases its players
+// so they can queue again. It returns ErrTicketNotQueued if the ticket
+// already left the queue.
+func (s *RedisPoolStore) SetStatus(ctx context.Context, ticket *entities.Ticket, status entities.TicketStatus, matchID string) error {
+	return s.setStatus(ctx, []*entities.Ticket{ticket}, status, matchID)
+}
+
+// SetMatched marks all tickets of a match matched at once, or none of them
+// with ErrTicketNotQueued when one already left the queue.
+func (s *RedisPoolStore) SetMatched(ctx context.Context, tickets []*entities.Ticket, matchID string) error {
+	return s.setStatus(ctx, tickets, entities.TicketMatched, matchID)
+}
+
+func (s *RedisPoolStore) setStatus(ctx context.Context, tickets []*entities.Ticket, status entities.TicketStatus, matchID string) error {
+	keys := make([]string, len(tickets))
+	for i, ticket := range tickets {
+		keys[i] = TicketKey(ticket.ID)
+	}
+
+	now := time.Now()
+	moved, err := setStatusScript.Run(ctx, s.redisClient, keys, string(status), matchID, now.Unix(), string(entities.TicketQueued)).Int()
+	if err != nil {
+		return err
+	}
+	if moved == 0 {
+		return ErrTicketNotQueued
+	}
+
+	for _, ticket := range tickets {
+		ticket.Status = status
+		ticket.MatchID = matchID
+		ticket.UpdatedAt = now
+		if err := releasePlayersScript.Run(ctx, s.redisClient, playerTicketKeys(ticket), ticket.ID).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
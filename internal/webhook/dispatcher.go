@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+	"matchmaker-nats/internal/store"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	HeaderSignature = "X-Matchmaker-Signature"
+	HeaderTimestamp = "X-Matchmaker-Timestamp"
+	HeaderMatchID   = "X-Matchmaker-Match-Id"
+	HeaderAttempt   = "X-Matchmaker-Attempt"
+
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// Dispatcher POSTs the matches of one queue to its webhook in the background,
+// retrying failed deliveries with exponential backoff.
+type Dispatcher struct {
+	config     queue.Webhook
+	queueName  string
+	httpClient *http.Client
+	deliveries store.DeliveryStore
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewDispatcher(deliveries store.DeliveryStore, queueName string, config queue.Webhook) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		config:     config,
+		queueName:  queueName,
+		httpClient: &http.Client{Timeout: config.Timeout},
+		deliveries: deliveries,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Dispatch starts delivering the match and returns right away.
+func (d *Dispatcher) Dispatch(match entities.Match) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.deliver(match)
+	}()
+}
+
+// Close abandons pending retries, recording their deliveries as failed, and
+// waits for in-flight deliveries.
+func (d *Dispatcher) Close() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) deliver(match entities.Match) {
+	body, contentType, err := d.encode(&match)
+	if err != nil {
+		log.Printf("[WEBHOOK] Failed to encode match %s: %v", match.MatchID, err)
+		return
+	}
+
+	delivery := &entities.Delivery{
+		MatchID: match.MatchID,
+		URL:     d.config.URL,
+		Status:  entities.DeliveryPending,
+	}
+	d.record(delivery)
+
+	backoff := d.config.Backoff
+	for delivery.Attempts < d.config.MaxAttempts {
+		delivery.Attempts++
+
+		statusCode, err := d.post(match.MatchID, delivery.Attempts, body, contentType)
+		delivery.StatusCode = statusCode
+		delivery.LastError = ""
+		if err == nil {
+			delivery.Status = entities.DeliveryDelivered
+			d.record(delivery)
+			log.Printf("[WEBHOOK] Match %s delivered to %s (attempt %d)", match.MatchID, d.config.URL, delivery.Attempts)
+			return
+		}
+		delivery.LastError = err.Error()
+
+		if !retryable(statusCode) || delivery.Attempts == d.config.MaxAttempts {
+			break
+		}
+		d.record(delivery)
+
+		log.Printf("[WEBHOOK] Delivery of match %s failed (attempt %d/%d), retrying in %s: %v", match.MatchID, delivery.Attempts, d.config.MaxAttempts, backoff, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-d.ctx.Done():
+			// Recorded as failed so the delivery doesn't stay pending forever.
+			delivery.Status = entities.DeliveryFailed
+			delivery.LastError = "dispatcher closed before retrying: " + delivery.LastError
+			d.record(delivery)
+			log.Printf("[WEBHOOK] Giving up on match %s: dispatcher closed", match.MatchID)
+			return
+		}
+		backoff *= 2
+		if d.config.MaxBackoff > 0 && backoff > d.config.MaxBackoff {
+			backoff = d.config.MaxBackoff
+		}
+	}
+
+	delivery.Status = entities.DeliveryFailed
+	d.record(delivery)
+	log.Printf("[WEBHOOK] Delivery of match %s to %s failed after %d attempts: %s", match.MatchID, d.config.URL, delivery.Attempts, delivery.LastError)
+}
+
+func (d *Dispatcher) encode(match *entities.Match) ([]byte, string, error) {
+	if d.config.Format == queue.WebhookProtobuf {
+		body, err := proto.Marshal(match.ToProto())
+		return body, ContentTypeProtobuf, err
+	}
+	body, err := match.ToJSON()
+	return body, ContentTypeJSON, err
+}
+
+// post sends one attempt and returns the response status, or 0 when no
+// response was received.
+func (d *Dispatcher) post(matchID string, attempt int, body []byte, contentType string) (int, error) {
+	// Not bound to d.ctx: Close lets a started attempt finish, bounded by the
+	// client timeout.
+	req, err := http.NewRequest(http.MethodPost, d.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(HeaderMatchID, matchID)
+	req.Header.Set(HeaderAttempt, strconv.Itoa(attempt))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if d.config.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(d.config.Secret, timestamp, body))
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) record(delivery *entities.Delivery) {
+	delivery.UpdatedAt = time.Now()
+	if err := d.deliveries.Save(context.Background(), d.queueName, delivery); err != nil {
+		log.Printf("[WEBHOOK] Failed to record delivery of match %s: %v", delivery.MatchID, err)
+	}
+}
+
+// Sign returns the HeaderSignature value for a body sent at timestamp:
+// "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>". Covering
+// the timestamp lets receivers reject replayed deliveries.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryable reports whether a failed attempt may succeed later: network
+// errors, timeouts, throttling and server errors are retried, other client
+// errors are not.
+func retryable(statusCode int) bool {
+	return statusCode == 0 ||
+		statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooManyRequests ||
+		statusCode >= 500
+}
@@ -0,0 +1,256 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/queue"
+	"matchmaker-nats/internal/store"
+)
+
+// recorder is a webhook endpoint answering with the given status codes in
+// turn, the last one repeating, and keeping every request it got.
+type recorder struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []recordedRequest
+}
+
+type recordedRequest struct {
+	at     time.Time
+	header http.Header
+	body   []byte
+}
+
+func newRecorder(t *testing.T, statuses ...int) (*recorder, *httptest.Server) {
+	t.Helper()
+
+	r := &recorder{statuses: statuses}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		status := r.statuses[min(len(r.requests), len(r.statuses)-1)]
+		r.requests = append(r.requests, recordedRequest{at: time.Now(), header: req.Header.Clone(), body: body})
+		r.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return r, srv
+}
+
+func (r *recorder) received() []recordedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]recordedRequest(nil), r.requests...)
+}
+
+func testConfig(url string) queue.Webhook {
+	return queue.Webhook{
+		URL:         url,
+		Format:      queue.WebhookJSON,
+		MaxAttempts: 4,
+		Backoff:     20 * time.Millisecond,
+		MaxBackoff:  30 * time.Millisecond,
+		Timeout:     time.Second,
+	}
+}
+
+func testMatch() entities.Match {
+	return entities.Match{
+		MatchID: "match_1",
+		Players: []entities.Player{{ID: "player-1"}, {ID: "player-2"}},
+	}
+}
+
+// waitForDelivery polls until the delivery of the match is no longer pending.
+func waitForDelivery(t *testing.T, deliveries store.DeliveryStore, matchID string) *entities.Delivery {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		delivery, err := deliveries.Get(t.Context(), matchID)
+		if err == nil && delivery.Status != entities.DeliveryPending {
+			return delivery
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("delivery of match %s still pending", matchID)
+	return nil
+}
+
+func TestDispatcherSignsDeliveries(t *testing.T) {
+	rec, srv := newRecorder(t, http.StatusOK)
+	deliveries := store.NewMemoryDeliveryStore()
+	config := testConfig(srv.URL)
+	config.Secret = "s3cret"
+
+	d := NewDispatcher(deliveries, "test", config)
+	defer d.Close()
+	d.Dispatch(testMatch())
+
+	delivery := waitForDelivery(t, deliveries, "match_1")
+	if delivery.Status != entities.DeliveryDelivered || delivery.Attempts != 1 {
+		t.Fatalf("delivery = %+v, want delivered on the first attempt", delivery)
+	}
+
+	req := rec.received()[0]
+	if got := req.header.Get("Content-Type"); got != ContentTypeJSON {
+		t.Errorf("content type = %q, want %q", got, ContentTypeJSON)
+	}
+	if got := req.header.Get(HeaderMatchID); got != "match_1" {
+		t.Errorf("match ID header = %q, want match_1", got)
+	}
+	if got := req.header.Get(HeaderAttempt); got != "1" {
+		t.Errorf("attempt header = %q, want 1", got)
+	}
+
+	timestamp := req.header.Get(HeaderTimestamp)
+	if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil {
+		t.Fatalf("timestamp header %q: %v", timestamp, err)
+	}
+
+	// Verified the way a receiver would, without Sign.
+	mac := hmac.New(sha256.New, []byte(config.Secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(req.body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := req.header.Get(HeaderSignature); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+}
+
+func TestDispatcherOmitsSignatureWithoutSecret(t *testing.T) {
+	rec, srv := newRecorder(t, http.StatusOK)
+	deliveries := store.NewMemoryDeliveryStore()
+
+	d := NewDispatcher(deliveries, "test", testConfig(srv.URL))
+	defer d.Close()
+	d.Dispatch(testMatch())
+
+	waitForDelivery(t, deliveries, "match_1")
+	if got := rec.received()[0].header.Get(HeaderSignature); got != "" {
+		t.Errorf("signature = %q, want none", got)
+	}
+}
+
+func TestDispatcherBacksOff(t *testing.T) {
+	rec, srv := newRecorder(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK)
+	deliveries := store.NewMemoryDeliveryStore()
+	config := testConfig(srv.URL)
+
+	d := NewDispatcher(deliveries, "test", config)
+	defer d.Close()
+	d.Dispatch(testMatch())
+
+	delivery := waitForDelivery(t, deliveries, "match_1")
+	if delivery.Status != entities.DeliveryDelivered || delivery.Attempts != 4 {
+		t.Fatalf("delivery = %+v, want delivered on attempt 4", delivery)
+	}
+
+	requests := rec.received()
+	// Backoff doubles from 20ms and is capped at 30ms.
+	waits := []time.Duration{20 * time.Millisecond, 30 * time.Millisecond, 30 * time.Millisecond}
+	for i, want := range waits {
+		if got := requests[i+1].at.Sub(requests[i].at); got < want {
+			t.Errorf("wait before attempt %d = %s, want at least %s", i+2, got, want)
+		}
+		if got := requests[i+1].header.Get(HeaderAttempt); got != strconv.Itoa(i+2) {
+			t.Errorf("attempt header = %q, want %d", got, i+2)
+		}
+	}
+}
+
+func TestDispatcherRetriesOnlyRetryableFailures(t *testing.T) {
+	tests := []struct {
+		status   int
+		attempts int
+	}{
+		{http.StatusBadRequest, 1},
+		{http.StatusUnauthorized, 1},
+		{http.StatusNotFound, 1},
+		{http.StatusRequestTimeout, 4},
+		{http.StatusTooManyRequests, 4},
+		{http.StatusInternalServerError, 4},
+		{http.StatusBadGateway, 4},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.status), func(t *testing.T) {
+			rec, srv := newRecorder(t, tt.status)
+			deliveries := store.NewMemoryDeliveryStore()
+
+			d := NewDispatcher(deliveries, "test", testConfig(srv.URL))
+			defer d.Close()
+			d.Dispatch(testMatch())
+
+			delivery := waitForDelivery(t, deliveries, "match_1")
+			if delivery.Status != entities.DeliveryFailed {
+				t.Errorf("status = %s, want failed", delivery.Status)
+			}
+			if delivery.Attempts != tt.attempts || len(rec.received()) != tt.attempts {
+				t.Errorf("attempts = %d (%d received), want %d", delivery.Attempts, len(rec.received()), tt.attempts)
+			}
+			if delivery.StatusCode != tt.status {
+				t.Errorf("status code = %d, want %d", delivery.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestDispatcherRetriesNetworkErrors(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+	deliveries := store.NewMemoryDeliveryStore()
+
+	d := NewDispatcher(deliveries, "test", testConfig(url))
+	defer d.Close()
+	d.Dispatch(testMatch())
+
+	delivery := waitForDelivery(t, deliveries, "match_1")
+	if delivery.Status != entities.DeliveryFailed || delivery.Attempts != 4 || delivery.StatusCode != 0 {
+		t.Errorf("delivery = %+v, want failed after 4 attempts without a response", delivery)
+	}
+}
+
+func TestDispatcherCloseFailsPendingRetries(t *testing.T) {
+	rec, srv := newRecorder(t, http.StatusServiceUnavailable)
+	deliveries := store.NewMemoryDeliveryStore()
+	config := testConfig(srv.URL)
+	config.Backoff, config.MaxBackoff = time.Hour, time.Hour
+
+	d := NewDispatcher(deliveries, "test", config)
+	d.Dispatch(testMatch())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rec.received()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("first attempt never arrived")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	d.Close()
+
+	delivery, err := deliveries.Get(t.Context(), "match_1")
+	if err != nil {
+		t.Fatalf("get delivery: %v", err)
+	}
+	if delivery.Status != entities.DeliveryFailed || delivery.Attempts != 1 {
+		t.Errorf("delivery = %+v, want failed after 1 attempt", delivery)
+	}
+	if !strings.Contains(delivery.LastError, "dispatcher closed") {
+		t.Errorf("last error = %q, want it to mention the closed dispatcher", delivery.LastError)
+	}
+}
@@ -2,88 +2,302 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"sync"
 	"time"
 
+	"matchmaker-nats/internal/broker"
 	"matchmaker-nats/internal/entities"
-
-	"github.com/go-redis/redis/v8"
-	"github.com/nats-io/nats.go"
+	"matchmaker-nats/internal/lease"
+	"matchmaker-nats/internal/matcher"
+	"matchmaker-nats/internal/queue"
+	"matchmaker-nats/internal/store"
+	"matchmaker-nats/internal/webhook"
 )
 
 const (
-	MatchmakeQueue = "matchmake"
-	playerPoolKey  = "player_pool"
-	natsSubject    = "matchmake.request"
-	MinPlayers     = 2
-	MaxPlayers     = 16
-	BatchSize      = 50
+	BatchSize = 50
+
+	// TicketTimeout is how long a ticket may wait in the pool before it is
+	// expired instead of matched.
+	TicketTimeout = 5 * time.Minute
 )
 
+// MatchmakeWorker forms matches for a single queue; run one per configured
+// queue.
 type MatchmakeWorker struct {
-	natsClient  *nats.Conn
-	redisClient *redis.Client
+	broker  broker.Broker
+	tickets store.PoolStore
+	matches store.MatchStore
+	config  queue.Config
+	matcher matcher.Matcher
+	// leases tells whether this replica owns the queue; nil means it always
+	// does.
+	leases *lease.Manager
+	// requests is only set while the queue is owned, so triggers reach the
+	// replica that matches them.
+	requests broker.Subscription
+	// webhook is nil unless the queue has a webhook configured.
+	webhook *webhook.Dispatcher
+
+	// trigger holds at most one pending wake-up of the loop, so a burst of
+	// triggers costs a single pool check.
+	trigger chan struct{}
+	// poolAfterPass is the pool size the last pass left behind; only tickets
+	// above it count towards the tick threshold.
+	poolAfterPass int64
+	done          chan struct{}
+	stopped       sync.WaitGroup
 }
 
-func NewMatchmakeWorker(natsClient *nats.Conn, redisClient *redis.Client) *MatchmakeWorker {
-	log.Printf("[WORKER] Initializing MatchmakeWorker")
-	return &MatchmakeWorker{
-		natsClient:  natsClient,
-		redisClient: redisClient,
+func NewMatchmakeWorker(b broker.Broker, stores store.Stores, leases *lease.Manager, config queue.Config) *MatchmakeWorker {
+	log.Printf("[WORKER] Initializing MatchmakeWorker for queue %s (players: %d-%d, max rating spread: %d, max latency: %dms)",
+		config.Name, config.MinPlayers, config.MaxPlayers, config.MaxRatingSpread, config.MaxLatency)
+	mw := &MatchmakeWorker{
+		broker:  b,
+		tickets: stores.Pool,
+		matches: stores.Matches,
+		config:  config,
+		leases:  leases,
+		trigger: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	if config.Webhook != nil {
+		log.Printf("[WORKER] Matches of queue %s are delivered to webhook %s as %s", config.Name, config.Webhook.URL, config.Webhook.Format)
+		mw.webhook = webhook.NewDispatcher(stores.Deliveries, config.Name, *config.Webhook)
 	}
+	return mw
 }
 
+// Start runs the queue's matchmaking loop. It only matches, and listens to
+// the queue's triggers, while this replica owns the queue.
 func (mw *MatchmakeWorker) Start() error {
-	log.Printf("[WORKER] Starting worker with queue: %s", MatchmakeQueue)
-
-	_, err := mw.natsClient.QueueSubscribe(natsSubject, MatchmakeQueue, func(msg *nats.Msg) {
-		log.Printf("[WORKER] Received NATS message, starting matchmaking process")
-		mw.processPlayerBatches()
-		msg.Ack()
-		log.Printf("[WORKER] NATS message acknowledged")
-	})
+	var err error
+	mw.matcher, err = matcher.New(mw.config)
+	if err != nil {
+		log.Printf("[WORKER] Failed to set up matcher for queue %s: %v", mw.config.Name, err)
+		return err
+	}
+	log.Printf("[WORKER] Queue %s uses the %s matcher", mw.config.Name, mw.config.Matcher)
+
+	log.Printf("[WORKER] Starting worker for queue %s, ticking every %s (early after %d new tickets)", mw.config.Name, mw.config.Tick.Interval, mw.config.TickThreshold())
+
+	mw.stopped.Add(1)
+	go mw.run()
+
+	return nil
+}
+
+// Stop stops pulling new triggers and ticking; a pass already running
+// finishes. Webhook deliveries in flight finish too, pending retries are
+// given up and recorded as failed.
+func (mw *MatchmakeWorker) Stop() {
+	close(mw.done)
+	mw.stopped.Wait()
+	if mw.webhook != nil {
+		mw.webhook.Close()
+	}
+}
+
+// handleRequest wakes the loop up to check whether the new ticket pushed the
+// pool over the tick threshold. Triggers arriving while a wake-up is pending
+// are coalesced into it; the pass itself never runs on the broker's time, so
+// acking only confirms the wake-up was received.
+//
+// A trigger is handed back while this replica doesn't own the queue, so it
+// reaches the owner, or when its ticket can't be loaded. Triggers of tickets
+// that already left the pool are acked without a wake-up.
+func (mw *MatchmakeWorker) handleRequest(req *entities.MatchRequest) error {
+	select {
+	case <-mw.done:
+		return fmt.Errorf("worker of queue %s is stopping", mw.config.Name)
+	default:
+	}
+	if mw.leases != nil && !mw.leases.Owns(mw.config.Name) {
+		return fmt.Errorf("queue %s is not owned by this replica", mw.config.Name)
+	}
+
+	handled, err := mw.alreadyHandled(req)
 	if err != nil {
-		log.Printf("[WORKER] Failed to subscribe to NATS queue: %v", err)
 		return err
 	}
+	if handled {
+		log.Printf("[WORKER] Ticket %s already left the pool, skipping wake-up", req.TicketID)
+		return nil
+	}
+
+	select {
+	case mw.trigger <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// alreadyHandled reports whether the request's ticket is no longer queued:
+// the pass that matched it, or the cancellation, leaves nothing for this
+// trigger to do. Requests without a ticket ID, from older publishers, always
+// wake the loop up.
+func (mw *MatchmakeWorker) alreadyHandled(req *entities.MatchRequest) (bool, error) {
+	if req.TicketID == "" {
+		return false, nil
+	}
+
+	ticket, err := mw.tickets.Get(context.Background(), req.TicketID)
+	if errors.Is(err, store.ErrTicketNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("load ticket %s: %w", req.TicketID, err)
+	}
+	return ticket.Status.Terminal(), nil
+}
+
+// run matches the pool every tick, and early when enough tickets arrived
+// since the last pass. Ownership is checked on every wake-up, so a queue
+// changing hands is picked up within a tick.
+func (mw *MatchmakeWorker) run() {
+	defer mw.stopped.Done()
+	defer mw.unsubscribe()
+
+	ticker := time.NewTicker(mw.config.Tick.Interval)
+	defer ticker.Stop()
+
+	mw.owns()
+	for {
+		select {
+		case <-mw.done:
+			return
+		case <-ticker.C:
+			if !mw.owns() {
+				continue
+			}
+			mw.tick()
+		case <-mw.trigger:
+			if !mw.owns() || !mw.thresholdReached() {
+				continue
+			}
+			log.Printf("[WORKER] %d new tickets in queue %s, matching before the next tick", mw.config.TickThreshold(), mw.config.Name)
+			mw.tick()
+			ticker.Reset(mw.config.Tick.Interval)
+		}
+	}
+}
+
+// owns reports whether this replica owns the queue and follows it with the
+// trigger subscription. Replicas subscribed at the same time share the
+// triggers; that only lasts until the old owner's next tick.
+func (mw *MatchmakeWorker) owns() bool {
+	owned := mw.leases == nil || mw.leases.Owns(mw.config.Name)
+
+	if owned && mw.requests == nil {
+		requests, err := mw.broker.SubscribeRequests(mw.config.Name, mw.handleRequest)
+		if err != nil {
+			log.Printf("[WORKER] Failed to subscribe to triggers of queue %s: %v", mw.config.Name, err)
+		} else {
+			log.Printf("[WORKER] Owning queue %s, listening for matchmaking triggers", mw.config.Name)
+			mw.requests = requests
+			// Whatever arrived since the last owner's pass counts.
+			mw.poolAfterPass = 0
+		}
+	} else if !owned && mw.requests != nil {
+		log.Printf("[WORKER] Queue %s is owned by another replica, no longer matching it", mw.config.Name)
+		mw.unsubscribe()
+	}
+
+	return owned
+}
+
+func (mw *MatchmakeWorker) unsubscribe() {
+	if mw.requests == nil {
+		return
+	}
+	if err := mw.requests.Unsubscribe(); err != nil {
+		log.Printf("[WORKER] Failed to unsubscribe from triggers of queue %s: %v", mw.config.Name, err)
+	}
+	mw.requests = nil
+}
+
+func (mw *MatchmakeWorker) thresholdReached() bool {
+	size, err := mw.tickets.Size(context.Background(), mw.config.Name)
+	if err != nil {
+		log.Printf("[WORKER] Could not get pool size of queue %s: %v", mw.config.Name, err)
+		return false
+	}
+	return size-mw.poolAfterPass >= int64(mw.config.TickThreshold())
+}
 
-	log.Printf("[WORKER] Successfully subscribed to NATS subject: %s", natsSubject)
-	log.Printf("[WORKER] Worker is now listening for messages...")
+// tick runs a pass over a non-empty pool. A failed pass is logged and simply
+// retried on the next tick.
+func (mw *MatchmakeWorker) tick() {
+	ctx := context.Background()
 
-	return err
+	size, err := mw.tickets.Size(ctx, mw.config.Name)
+	if err != nil {
+		log.Printf("[WORKER] Could not get pool size of queue %s: %v", mw.config.Name, err)
+		return
+	}
+	if size > 0 {
+		if err := mw.processPlayerBatches(); err != nil {
+			log.Printf("[WORKER] Matchmaking pass for queue %s failed: %v", mw.config.Name, err)
+		}
+		size, err = mw.tickets.Size(ctx, mw.config.Name)
+		if err != nil {
+			size = 0
+		}
+	}
+	mw.poolAfterPass = size
 }
 
-func (mw *MatchmakeWorker) processPlayerBatches() {
+func (mw *MatchmakeWorker) processPlayerBatches() error {
 	log.Printf("[WORKER] Starting player batch processing")
 	ctx := context.Background()
 
+	mw.recoverClaims(ctx)
+	mw.expireStaleTickets(ctx)
+
 	batchCount := 0
 	totalPlayersProcessed := 0
 
+	// Unmatched tickets are only returned once the pass is over. Requeued
+	// right away they would be the oldest in the pool and claimed again by
+	// the next batch, forever if a whole batch can't be matched.
+	var leftovers []store.PoolEntry
+	defer func() {
+		mw.requeue(ctx, leftovers)
+	}()
+
 	for {
 		batchCount++
 		log.Printf("[WORKER] Processing batch #%d", batchCount)
 
-		result, err := mw.redisClient.ZRangeWithScores(ctx, playerPoolKey, 0, BatchSize-1).Result()
+		// Claiming pops the tickets, so workers sharing the consumer never
+		// build matches out of the same players.
+		// The matcher enforces MinPlayers: a batch of parties holds more
+		// players than tickets.
+		result, err := mw.tickets.ClaimBatch(ctx, mw.config.Name, BatchSize)
 		if err != nil {
 			log.Printf("[WORKER] Error getting player batch #%d: %v", batchCount, err)
-			return
+			return fmt.Errorf("claim batch: %w", err)
 		}
 
-		if len(result) < MinPlayers {
-			log.Printf("[WORKER] Batch #%d has insufficient players: %d (minimum: %d)", batchCount, len(result), MinPlayers)
+		if len(result) == 0 {
+			log.Printf("[WORKER] Batch #%d is empty, pool is drained", batchCount)
 			break
 		}
 
-		log.Printf("[WORKER] Batch #%d contains %d players", batchCount, len(result))
+		log.Printf("[WORKER] Batch #%d contains %d tickets", batchCount, len(result))
 
-		matches := mw.processBatch(ctx, result)
+		matches, left := mw.processBatch(ctx, result)
+		leftovers = append(leftovers, left...)
 		totalPlayersProcessed += len(result)
 
 		log.Printf("[WORKER] Batch #%d created %d matches", batchCount, len(matches))
 
 		for _, match := range matches {
 			log.Printf("[WORKER] Created match %s with %d players", match.MatchID, len(match.Players))
+			mw.publishMatch(match)
 		}
 
 		if len(result) < BatchSize {
@@ -93,82 +307,187 @@ func (mw *MatchmakeWorker) processPlayerBatches() {
 	}
 
 	log.Printf("[WORKER] Batch processing completed - Total batches: %d, Total players processed: %d", batchCount, totalPlayersProcessed)
+	return nil
 }
 
-func (mw *MatchmakeWorker) processBatch(ctx context.Context, players []redis.Z) []entities.Match {
-	log.Printf("[WORKER] Processing batch of %d players", len(players))
+// processBatch matches the claimed tickets and returns the matches along with
+// the entries of the tickets left over.
+func (mw *MatchmakeWorker) processBatch(ctx context.Context, members []store.PoolEntry) ([]entities.Match, []store.PoolEntry) {
+	log.Printf("[WORKER] Processing batch of %d tickets", len(members))
+
+	ticketsByPlayer := make(map[string]*entities.Ticket, len(members))
+	scoresByTicket := make(map[string]float64, len(members))
+	tickets := make([]*entities.Ticket, 0, len(members))
+	// Tickets that failed to load go back to the pool with the leftovers, so
+	// they are retried and expire like any other.
+	var unloaded []store.PoolEntry
+	var gone []string
+	for _, entry := range members {
+		ticketID := entry.TicketID
+
+		ticket, err := mw.tickets.Get(ctx, ticketID)
+		if errors.Is(err, store.ErrTicketNotFound) {
+			log.Printf("[WORKER] Dropping ticket %s (score: %.0f): %v", ticketID, entry.Score, err)
+			gone = append(gone, ticketID)
+			continue
+		}
+		if err != nil {
+			log.Printf("[WORKER] Returning ticket %s (score: %.0f) to pool: %v", ticketID, entry.Score, err)
+			unloaded = append(unloaded, entry)
+			continue
+		}
+		// Cancelled while claimed, or requeued by a pass that raced the
+		// cancellation.
+		if ticket.Status.Terminal() {
+			log.Printf("[WORKER] Dropping ticket %s: already %s", ticketID, ticket.Status)
+			gone = append(gone, ticketID)
+			continue
+		}
 
-	playerEntities := make([]entities.Player, 0, len(players))
-	for _, z := range players {
-		playerID := z.Member.(string)
-		score := z.Score
-		player := entities.Player{
-			ID:   playerID,
-			Ping: 0,
+		for _, player := range ticket.Players() {
+			ticketsByPlayer[player.ID] = ticket
 		}
-		playerEntities = append(playerEntities, player)
-		log.Printf("[WORKER] Player %s (score: %.0f) added to batch", playerID, score)
+		scoresByTicket[ticket.ID] = entry.Score
+		tickets = append(tickets, ticket)
+		log.Printf("[WORKER] Player %s (ticket: %s, party size: %d, score: %.0f) added to batch", ticket.Player.ID, ticketID, ticket.Size(), entry.Score)
 	}
 
-	log.Printf("[WORKER] Creating optimal matches from %d players", len(playerEntities))
-	matches := mw.createOptimalMatches(playerEntities)
-
-	playerIDs := make([]interface{}, len(playerEntities))
-	for i, player := range playerEntities {
-		playerIDs[i] = player.ID
+	log.Printf("[WORKER] Creating optimal matches from %d players", len(tickets))
+	matches, leftovers := mw.matcher.Match(tickets, time.Now())
+
+	// Unmatched players go back with their original enqueue time so they
+	// keep their place at the front of the FIFO.
+	requeue := unloaded
+	for _, ticket := range leftovers {
+		requeue = append(requeue, store.PoolEntry{
+			TicketID: ticket.ID,
+			Score:    scoresByTicket[ticket.ID],
+		})
 	}
 
-	log.Printf("[WORKER] Removing %d matched players from Redis pool", len(playerIDs))
-	mw.redisClient.ZRem(ctx, playerPoolKey, playerIDs...)
+	formed := make([]entities.Match, 0, len(matches))
+	for _, match := range matches {
+		var matchTickets []*entities.Ticket
+		for _, player := range match.Players {
+			ticket := ticketsByPlayer[player.ID]
+			if ticket.Player.ID == player.ID { // party members share their leader's ticket
+				matchTickets = append(matchTickets, ticket)
+			}
+		}
 
-	return matches
-}
+		// All tickets flip at once, so a ticket cancelled since it was
+		// loaded can't end up in a match.
+		if err := mw.tickets.SetMatched(ctx, matchTickets, match.MatchID); err != nil {
+			log.Printf("[WORKER] Dropping match %s: %v", match.MatchID, err)
+			for _, ticket := range matchTickets {
+				if mw.stillQueued(ctx, ticket.ID) {
+					requeue = append(requeue, store.PoolEntry{TicketID: ticket.ID, Score: scoresByTicket[ticket.ID]})
+				} else {
+					gone = append(gone, ticket.ID)
+				}
+			}
+			continue
+		}
+		for _, ticket := range matchTickets {
+			gone = append(gone, ticket.ID)
+		}
 
-func (mw *MatchmakeWorker) createOptimalMatches(players []entities.Player) []entities.Match {
-	log.Printf("[WORKER] Creating optimal matches for %d players", len(players))
+		// A client seeing the status before the match is stored retries the
+		// lookup on its next refresh.
+		if err := mw.matches.Save(ctx, &match); err != nil {
+			log.Printf("[WORKER] Failed to store match %s: %v", match.MatchID, err)
+		}
+		formed = append(formed, match)
+	}
 
-	var matches []entities.Match
-	remainingPlayers := players
+	if err := mw.tickets.Release(ctx, mw.config.Name, gone); err != nil {
+		log.Printf("[WORKER] Failed to release claims of %d tickets: %v", len(gone), err)
+	}
 
-	for len(remainingPlayers) >= MinPlayers {
-		matchSize := mw.calculateOptimalMatchSize(len(remainingPlayers))
-		log.Printf("[WORKER] Optimal match size for %d remaining players: %d", len(remainingPlayers), matchSize)
+	return formed, requeue
+}
 
-		matchPlayers := remainingPlayers[:matchSize]
-		remainingPlayers = remainingPlayers[matchSize:]
+// stillQueued reports whether a ticket of a dropped match should go back to
+// the pool. Tickets that can't be loaded go back too; the next pass sorts
+// them out.
+func (mw *MatchmakeWorker) stillQueued(ctx context.Context, ticketID string) bool {
+	ticket, err := mw.tickets.Get(ctx, ticketID)
+	if errors.Is(err, store.ErrTicketNotFound) {
+		return false
+	}
+	return err != nil || !ticket.Status.Terminal()
+}
 
-		match := entities.Match{
-			MatchID:   generateMatchID(),
-			Players:   matchPlayers,
-			CreatedAt: time.Now(),
-		}
-		matches = append(matches, match)
+// requeue returns the tickets left over by a pass to the pool.
+func (mw *MatchmakeWorker) requeue(ctx context.Context, entries []store.PoolEntry) {
+	if len(entries) == 0 {
+		return
+	}
 
-		log.Printf("[WORKER] Match %s created with %d players", match.MatchID, len(matchPlayers))
+	log.Printf("[WORKER] Returning %d unmatched tickets to pool", len(entries))
+	if err := mw.tickets.Requeue(ctx, mw.config.Name, entries); err != nil {
+		log.Printf("[WORKER] Failed to return unmatched tickets to pool: %v", err)
 	}
+}
 
-	log.Printf("[WORKER] Created %d total matches", len(matches))
-	return matches
+// recoverClaims returns to the pool the tickets a worker claimed and never
+// matched or requeued, most likely because it died mid-pass.
+func (mw *MatchmakeWorker) recoverClaims(ctx context.Context) {
+	recovered, err := mw.tickets.RecoverClaims(ctx, mw.config.Name, time.Now().Add(-store.ClaimTimeout))
+	if err != nil {
+		log.Printf("[WORKER] Error recovering abandoned claims: %v", err)
+		return
+	}
+	if len(recovered) > 0 {
+		log.Printf("[WORKER] Returned %d tickets claimed over %s ago to pool", len(recovered), store.ClaimTimeout)
+	}
 }
 
-func (mw *MatchmakeWorker) calculateOptimalMatchSize(totalPlayers int) int {
-	if totalPlayers <= MaxPlayers {
-		log.Printf("[WORKER] Using all %d players (within max limit)", totalPlayers)
-		return totalPlayers
+// expireStaleTickets drops tickets that waited longer than TicketTimeout and
+// marks them expired.
+func (mw *MatchmakeWorker) expireStaleTickets(ctx context.Context) {
+	stale, err := mw.tickets.Stale(ctx, mw.config.Name, time.Now().Add(-TicketTimeout))
+	if err != nil {
+		log.Printf("[WORKER] Error looking up stale tickets: %v", err)
+		return
 	}
 
-	if totalPlayers >= 24 {
-		log.Printf("[WORKER] Large group (%d players) - creating match of 12", totalPlayers)
-		return 12
-	} else if totalPlayers >= 18 {
-		log.Printf("[WORKER] Medium group (%d players) - creating match of 9", totalPlayers)
-		return 9
-	} else {
-		log.Printf("[WORKER] Small group (%d players) - using all players", totalPlayers)
-		return totalPlayers
+	for _, ticketID := range stale {
+		ticket, err := mw.tickets.Get(ctx, ticketID)
+		if err != nil {
+			log.Printf("[WORKER] Expired ticket %s could not be loaded: %v", ticketID, err)
+			mw.tickets.Dequeue(ctx, &entities.Ticket{ID: ticketID, Queue: mw.config.Name})
+			continue
+		}
+
+		removed, err := mw.tickets.Dequeue(ctx, ticket)
+		if err != nil || !removed {
+			continue
+		}
+
+		err = mw.tickets.SetStatus(ctx, ticket, entities.TicketExpired, "")
+		if errors.Is(err, store.ErrTicketNotQueued) {
+			continue // cancelled while it sat in the pool
+		}
+		if err != nil {
+			log.Printf("[WORKER] Failed to mark ticket %s as expired: %v", ticketID, err)
+			continue
+		}
+		log.Printf("[WORKER] Ticket %s for player %s expired after %s", ticketID, ticket.Player.ID, TicketTimeout)
 	}
 }
 
-func generateMatchID() string {
-	return "match_" + time.Now().Format("20060102150405")
+// publishMatch announces the match to the API instances. Failures are
+// logged; the players are already out of the pool at this point so there is
+// nothing to roll back.
+func (mw *MatchmakeWorker) publishMatch(match entities.Match) {
+	if err := mw.broker.PublishMatch(context.Background(), &match); err != nil {
+		log.Printf("[WORKER] Failed to publish match %s: %v", match.MatchID, err)
+	} else {
+		log.Printf("[WORKER] Match %s published", match.MatchID)
+	}
+
+	if mw.webhook != nil {
+		mw.webhook.Dispatch(match)
+	}
 }
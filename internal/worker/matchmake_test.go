@@ -0,0 +1,331 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"matchmaker-nats/internal/broker"
+	"matchmaker-nats/internal/entities"
+	"matchmaker-nats/internal/lease"
+	"matchmaker-nats/internal/matcher"
+	"matchmaker-nats/internal/queue"
+	"matchmaker-nats/internal/store"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestConcurrentWorkersShareThePool(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		testConcurrentWorkers(t, store.NewMemoryStores())
+	})
+	t.Run("redis", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { rdb.Close() })
+		testConcurrentWorkers(t, store.NewRedisStores(rdb))
+	})
+}
+
+// testConcurrentWorkers runs passes of several workers over the same pool at
+// once and checks every player ends up in exactly one match.
+func testConcurrentWorkers(t *testing.T, stores store.Stores) {
+	const (
+		workers  = 4
+		passes   = 3
+		tickets  = 200
+		perMatch = 10
+	)
+	ctx := context.Background()
+	config := queue.Default("test")
+	config.MinPlayers, config.MaxPlayers = perMatch, perMatch
+
+	created := time.Now().Add(-time.Minute)
+	for i := 0; i < tickets; i++ {
+		ticket := &entities.Ticket{
+			ID:        fmt.Sprintf("ticket-%03d", i),
+			Player:    entities.Player{ID: fmt.Sprintf("player-%03d", i), MMR: 1500},
+			Queue:     config.Name,
+			Status:    entities.TicketQueued,
+			CreatedAt: created.Add(time.Duration(i) * time.Millisecond),
+		}
+		if _, _, err := stores.Pool.Enqueue(ctx, ticket, ""); err != nil {
+			t.Fatalf("enqueue %s: %v", ticket.ID, err)
+		}
+	}
+
+	b := broker.NewMemoryBroker()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		mw := NewMatchmakeWorker(b, stores, nil, config)
+		var err error
+		if mw.matcher, err = matcher.New(config); err != nil {
+			t.Fatalf("matcher: %v", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := 0; p < passes; p++ {
+				if err := mw.processPlayerBatches(); err != nil {
+					t.Errorf("pass: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if size, err := stores.Pool.Size(ctx, config.Name); err != nil || size != 0 {
+		t.Fatalf("pool size = %d, %v; want 0", size, err)
+	}
+	recovered, err := stores.Pool.RecoverClaims(ctx, config.Name, time.Now().Add(time.Hour))
+	if err != nil || len(recovered) != 0 {
+		t.Fatalf("claims left behind = %v, %v; want none", recovered, err)
+	}
+
+	for i := 0; i < tickets; i++ {
+		playerID := fmt.Sprintf("player-%03d", i)
+		matches, _, err := stores.Matches.ListByPlayer(ctx, playerID, 10, "")
+		if err != nil {
+			t.Fatalf("matches of %s: %v", playerID, err)
+		}
+		if len(matches) != 1 {
+			t.Errorf("%s is in %d matches, want 1", playerID, len(matches))
+		}
+
+		ticket, err := stores.Pool.Get(ctx, fmt.Sprintf("ticket-%03d", i))
+		if err != nil {
+			t.Fatalf("get ticket: %v", err)
+		}
+		if ticket.Status != entities.TicketMatched {
+			t.Errorf("ticket %s is %s, want matched", ticket.ID, ticket.Status)
+		}
+	}
+}
+
+func TestAbandonedClaimsAreRecovered(t *testing.T) {
+	ctx := context.Background()
+	stores := store.NewMemoryStores()
+	config := queue.Default("test")
+
+	for i := 0; i < 3; i++ {
+		ticket := &entities.Ticket{
+			ID:        fmt.Sprintf("ticket-%d", i),
+			Player:    entities.Player{ID: fmt.Sprintf("player-%d", i), MMR: 1500},
+			Queue:     config.Name,
+			Status:    entities.TicketQueued,
+			CreatedAt: time.Now(),
+		}
+		if _, _, err := stores.Pool.Enqueue(ctx, ticket, ""); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+
+	// A worker dying here leaves the tickets out of the pool.
+	if _, err := stores.Pool.ClaimBatch(ctx, config.Name, BatchSize); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+
+	recovered, err := stores.Pool.RecoverClaims(ctx, config.Name, time.Now().Add(-store.ClaimTimeout))
+	if err != nil || len(recovered) != 0 {
+		t.Fatalf("recovered fresh claims %v, %v", recovered, err)
+	}
+
+	recovered, err = stores.Pool.RecoverClaims(ctx, config.Name, time.Now())
+	if err != nil || len(recovered) != 3 {
+		t.Fatalf("recovered %v, %v; want 3 tickets", recovered, err)
+	}
+	if size, _ := stores.Pool.Size(ctx, config.Name); size != 3 {
+		t.Fatalf("pool size = %d, want 3", size)
+	}
+}
+
+// failingPool fails every ticket lookup.
+type failingPool struct {
+	store.PoolStore
+}
+
+func (failingPool) Get(ctx context.Context, ticketID string) (*entities.Ticket, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func TestHandleRequest(t *testing.T) {
+	ctx := context.Background()
+	config := queue.Default("test")
+
+	newWorker := func(t *testing.T) (*MatchmakeWorker, store.Stores) {
+		stores := store.NewMemoryStores()
+		for id, status := range map[string]entities.TicketStatus{"queued": entities.TicketQueued, "matched": entities.TicketMatched} {
+			ticket := &entities.Ticket{ID: id, Player: entities.Player{ID: "player-" + id}, Queue: config.Name, Status: entities.TicketQueued, CreatedAt: time.Now()}
+			if _, _, err := stores.Pool.Enqueue(ctx, ticket, ""); err != nil {
+				t.Fatalf("enqueue: %v", err)
+			}
+			if status != entities.TicketQueued {
+				if err := stores.Pool.SetStatus(ctx, ticket, status, "match_1"); err != nil {
+					t.Fatalf("set status: %v", err)
+				}
+			}
+		}
+		return NewMatchmakeWorker(broker.NewMemoryBroker(), stores, nil, config), stores
+	}
+	woken := func(mw *MatchmakeWorker) bool {
+		select {
+		case <-mw.trigger:
+			return true
+		default:
+			return false
+		}
+	}
+
+	t.Run("queued ticket wakes the loop", func(t *testing.T) {
+		mw, _ := newWorker(t)
+		if err := mw.handleRequest(&entities.MatchRequest{TicketID: "queued"}); err != nil {
+			t.Fatalf("handleRequest: %v", err)
+		}
+		if !woken(mw) {
+			t.Error("loop not woken up")
+		}
+	})
+
+	t.Run("request without ticket wakes the loop", func(t *testing.T) {
+		mw, _ := newWorker(t)
+		if err := mw.handleRequest(&entities.MatchRequest{}); err != nil {
+			t.Fatalf("handleRequest: %v", err)
+		}
+		if !woken(mw) {
+			t.Error("loop not woken up")
+		}
+	})
+
+	t.Run("handled tickets are acked without a wake-up", func(t *testing.T) {
+		mw, _ := newWorker(t)
+		for _, ticketID := range []string{"matched", "missing"} {
+			if err := mw.handleRequest(&entities.MatchRequest{TicketID: ticketID}); err != nil {
+				t.Fatalf("handleRequest(%s): %v", ticketID, err)
+			}
+			if woken(mw) {
+				t.Errorf("loop woken up for %s ticket", ticketID)
+			}
+		}
+	})
+
+	t.Run("unloadable ticket is handed back", func(t *testing.T) {
+		mw, _ := newWorker(t)
+		mw.tickets = failingPool{mw.tickets}
+		if err := mw.handleRequest(&entities.MatchRequest{TicketID: "queued"}); err == nil {
+			t.Error("handleRequest succeeded, want an error for redelivery")
+		}
+	})
+
+	t.Run("trigger is handed back when the queue is owned elsewhere", func(t *testing.T) {
+		mw, stores := newWorker(t)
+		// Never started, so it holds no lease.
+		mw.leases = lease.NewManager(stores.Leases, "replica-1", lease.DefaultTTL, []string{config.Name})
+		if err := mw.handleRequest(&entities.MatchRequest{TicketID: "queued"}); err == nil {
+			t.Error("handleRequest succeeded, want an error for redelivery")
+		}
+		if woken(mw) {
+			t.Error("loop woken up without owning the queue")
+		}
+	})
+
+	t.Run("trigger is handed back while stopping", func(t *testing.T) {
+		mw, _ := newWorker(t)
+		close(mw.done)
+		if err := mw.handleRequest(&entities.MatchRequest{TicketID: "queued"}); err == nil {
+			t.Error("handleRequest succeeded, want an error for redelivery")
+		}
+	})
+}
+
+// cancellingPool cancels a ticket right after a pass loads it, the way a
+// client racing the pass would.
+type cancellingPool struct {
+	store.PoolStore
+	ticketID string
+}
+
+func (p cancellingPool) Get(ctx context.Context, ticketID string) (*entities.Ticket, error) {
+	ticket, err := p.PoolStore.Get(ctx, ticketID)
+	if err != nil || ticketID != p.ticketID || ticket.Status.Terminal() {
+		return ticket, err
+	}
+	cancelled := *ticket
+	if err := p.PoolStore.SetStatus(ctx, &cancelled, entities.TicketCancelled, ""); err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+func TestCancelledTicketsLeaveThePass(t *testing.T) {
+	ctx := context.Background()
+	config := queue.Default("test")
+	config.MinPlayers, config.MaxPlayers = 4, 4
+
+	newWorker := func(t *testing.T) (*MatchmakeWorker, store.Stores) {
+		stores := store.NewMemoryStores()
+		for i := 0; i < 4; i++ {
+			ticket := &entities.Ticket{
+				ID:        fmt.Sprintf("ticket-%d", i),
+				Player:    entities.Player{ID: fmt.Sprintf("player-%d", i), MMR: 1500},
+				Queue:     config.Name,
+				Status:    entities.TicketQueued,
+				CreatedAt: time.Now().Add(time.Duration(i) * time.Millisecond),
+			}
+			if _, _, err := stores.Pool.Enqueue(ctx, ticket, ""); err != nil {
+				t.Fatalf("enqueue: %v", err)
+			}
+		}
+		mw := NewMatchmakeWorker(broker.NewMemoryBroker(), stores, nil, config)
+		var err error
+		if mw.matcher, err = matcher.New(config); err != nil {
+			t.Fatalf("matcher: %v", err)
+		}
+		return mw, stores
+	}
+	check := func(t *testing.T, stores store.Stores) {
+		t.Helper()
+		if size, err := stores.Pool.Size(ctx, config.Name); err != nil || size != 3 {
+			t.Errorf("pool size = %d, %v; want the 3 other tickets", size, err)
+		}
+		if recovered, err := stores.Pool.RecoverClaims(ctx, config.Name, time.Now().Add(time.Hour)); err != nil || len(recovered) != 0 {
+			t.Errorf("claims left behind = %v, %v; want none", recovered, err)
+		}
+		for i := 0; i < 4; i++ {
+			want := entities.TicketQueued
+			if i == 0 {
+				want = entities.TicketCancelled
+			}
+			ticket, err := stores.Pool.Get(ctx, fmt.Sprintf("ticket-%d", i))
+			if err != nil || ticket.Status != want || ticket.MatchID != "" {
+				t.Errorf("ticket-%d = %+v, %v; want %s without a match", i, ticket, err, want)
+			}
+		}
+	}
+
+	t.Run("cancelled while claimed", func(t *testing.T) {
+		mw, stores := newWorker(t)
+		// The cancel flipped the status but the ticket was out of the pool.
+		ticket, _ := stores.Pool.Get(ctx, "ticket-0")
+		if err := stores.Pool.SetStatus(ctx, ticket, entities.TicketCancelled, ""); err != nil {
+			t.Fatalf("cancel: %v", err)
+		}
+		if err := mw.processPlayerBatches(); err != nil {
+			t.Fatalf("pass: %v", err)
+		}
+		check(t, stores)
+	})
+
+	t.Run("cancelled after being loaded", func(t *testing.T) {
+		mw, stores := newWorker(t)
+		mw.tickets = cancellingPool{PoolStore: mw.tickets, ticketID: "ticket-0"}
+		if err := mw.processPlayerBatches(); err != nil {
+			t.Fatalf("pass: %v", err)
+		}
+		check(t, stores)
+	})
+}
@@ -3,16 +3,43 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"matchmaker-nats/internal/broker"
 	"matchmaker-nats/internal/handler"
+	"matchmaker-nats/internal/lease"
+	"matchmaker-nats/internal/notify"
+	"matchmaker-nats/internal/queue"
+	"matchmaker-nats/internal/store"
 	"matchmaker-nats/internal/worker"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc"
 )
 
+// Modes the binary runs in, picked with MODE. WORKER=true is still honoured
+// as MODE=worker.
+const (
+	modeAPI    = "api"
+	modeWorker = "worker"
+	// modeAll runs API and workers together on in-memory storage and broker,
+	// without Redis or NATS.
+	modeAll = "all"
+)
+
+// shutdownTimeout bounds how long open HTTP requests and gRPC calls, event
+// streams included, may hold up shutdown before they are cut off.
+const shutdownTimeout = 10 * time.Second
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -20,6 +47,75 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("[MAIN] Invalid value for %s: %v", key, err)
+	}
+	return parsed
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("[MAIN] Invalid value for %s: %v", key, err)
+	}
+	return parsed
+}
+
+// replicaID names this process among the workers sharing the queues. The
+// suffix keeps a restarted container from taking over its old leases.
+func replicaID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
+	}
+	return hostname + "-" + uuid.NewString()[:8]
+}
+
+// loadQueues reads the queue definitions from QUEUES_CONFIG. Without it a
+// single default queue is configured from the environment.
+func loadQueues() []queue.Config {
+	if path := os.Getenv("QUEUES_CONFIG"); path != "" {
+		queues, err := queue.Load(path)
+		if err != nil {
+			log.Fatalf("[MAIN] Failed to load queues from %s: %v", path, err)
+		}
+		log.Printf("[MAIN] Loaded %d queues from %s", len(queues), path)
+		return queues
+	}
+
+	config := queue.Default(queue.DefaultName)
+	config.Matcher = getEnv("MATCHER", config.Matcher)
+	config.MinPlayers = getEnvInt("MIN_PLAYERS", config.MinPlayers)
+	config.MaxPlayers = getEnvInt("MAX_PLAYERS", config.MaxPlayers)
+	config.MaxRatingSpread = getEnvInt("MAX_RATING_SPREAD", config.MaxRatingSpread)
+	config.MaxLatency = getEnvInt("MAX_LATENCY_MS", config.MaxLatency)
+	config.Widening.Schedule = getEnv("WIDEN_SCHEDULE", config.Widening.Schedule)
+	config.Widening.Interval = getEnvDuration("WIDEN_INTERVAL", config.Widening.Interval)
+	config.Widening.RatingStep = getEnvInt("WIDEN_RATING_STEP", config.Widening.RatingStep)
+	config.Widening.LatencyStep = getEnvInt("WIDEN_LATENCY_STEP", config.Widening.LatencyStep)
+	config.Widening.MaxRatingSpread = getEnvInt("WIDEN_MAX_RATING_SPREAD", config.Widening.MaxRatingSpread)
+	config.Widening.MaxLatency = getEnvInt("WIDEN_MAX_LATENCY_MS", config.Widening.MaxLatency)
+	config.TeamCount = getEnvInt("TEAM_COUNT", config.TeamCount)
+	config.TeamSize = getEnvInt("TEAM_SIZE", config.TeamSize)
+	config.Tick.Interval = getEnvDuration("TICK_INTERVAL", config.Tick.Interval)
+	config.Tick.Threshold = getEnvInt("TICK_THRESHOLD", config.Tick.Threshold)
+
+	if err := config.Validate(); err != nil {
+		log.Fatalf("[MAIN] Invalid queue configuration: %v", err)
+	}
+	return []queue.Config{config}
+}
+
 func main() {
 	log.Printf("[MAIN] Starting Matchmaker application...")
 
@@ -29,60 +125,114 @@ func main() {
 	redisPassword := getEnv("REDIS_PASSWORD", "")
 	natsURL := getEnv("NATS_URL", nats.DefaultURL)
 	appPort := getEnv("APP_PORT", "8080")
+	grpcPort := getEnv("GRPC_PORT", "9090")
 
-	log.Printf("[MAIN] Configuration loaded - Redis: %s:%s, NATS: %s, Port: %s", redisHost, redisPort, natsURL, appPort)
+	mode := getEnv("MODE", modeAPI)
+	if os.Getenv("WORKER") == "true" {
+		mode = modeWorker
+	}
 
-	redisAddr := redisHost + ":" + redisPort
+	log.Printf("[MAIN] Configuration loaded - Mode: %s, Redis: %s:%s, NATS: %s, Port: %s, gRPC port: %s", mode, redisHost, redisPort, natsURL, appPort, grpcPort)
 
-	log.Printf("[MAIN] Connecting to Redis at %s", redisAddr)
+	var (
+		rdb    *redis.Client
+		nc     *nats.Conn
+		stores store.Stores
+		b      broker.Broker
+		err    error
+	)
+	// Cancelled on SIGINT or SIGTERM, so the deferred shutdown steps run:
+	// workers finish their pass and leases are handed over right away. Once
+	// cancelled, a second signal kills the process as usual.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Create Redis client
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     redisAddr,
-		Password: redisPassword,
-		DB:       0,
-	})
-	ctx := context.Background()
+	switch mode {
+	case modeAll:
+		// Everything lives in this process; state is lost on restart.
+		log.Printf("[MAIN] Running API and workers in one process with in-memory storage, Redis and NATS are not used")
+		stores = store.NewMemoryStores()
+		b = broker.NewMemoryBroker()
+	case modeAPI, modeWorker:
+		redisAddr := redisHost + ":" + redisPort
 
-	defer func() {
-		log.Printf("[MAIN] Closing Redis connection")
-		rdb.Close()
-	}()
+		log.Printf("[MAIN] Connecting to Redis at %s", redisAddr)
 
-	// Test Redis connection
-	log.Printf("[MAIN] Testing Redis connection...")
-	if err := rdb.Ping(ctx).Err(); err != nil {
-		log.Fatalf("[MAIN] Failed to connect to Redis: %v", err)
-	}
-	log.Printf("[MAIN] Redis connection successful")
+		// Create Redis client
+		rdb = redis.NewClient(&redis.Options{
+			Addr:     redisAddr,
+			Password: redisPassword,
+			DB:       0,
+		})
+		defer func() {
+			log.Printf("[MAIN] Closing Redis connection")
+			rdb.Close()
+		}()
 
-	// Connect to NATS
-	log.Printf("[MAIN] Connecting to NATS at %s", natsURL)
-	nc, err := nats.Connect(natsURL)
-	if err != nil {
-		log.Fatalf("[MAIN] Failed to connect to NATS: %v", err)
-	}
-	defer func() {
-		log.Printf("[MAIN] Closing NATS connection")
-		nc.Close()
-	}()
+		// Test Redis connection
+		log.Printf("[MAIN] Testing Redis connection...")
+		if err := rdb.Ping(ctx).Err(); err != nil {
+			log.Fatalf("[MAIN] Failed to connect to Redis: %v", err)
+		}
+		log.Printf("[MAIN] Redis connection successful")
 
-	// Check NATS connection
-	if nc.IsConnected() {
-		log.Printf("[MAIN] NATS connection successful")
-	} else {
-		log.Fatalf("[MAIN] NATS connection failed")
+		// Connect to NATS
+		log.Printf("[MAIN] Connecting to NATS at %s", natsURL)
+		nc, err = nats.Connect(natsURL)
+		if err != nil {
+			log.Fatalf("[MAIN] Failed to connect to NATS: %v", err)
+		}
+		defer func() {
+			log.Printf("[MAIN] Closing NATS connection")
+			nc.Close()
+		}()
+
+		// Check NATS connection
+		if nc.IsConnected() {
+			log.Printf("[MAIN] NATS connection successful")
+		} else {
+			log.Fatalf("[MAIN] NATS connection failed")
+		}
+
+		log.Printf("[MAIN] Setting up JetStream streams...")
+		js, err := broker.NewJetStream(ctx, nc)
+		if err != nil {
+			log.Fatalf("[MAIN] Failed to set up JetStream: %v", err)
+		}
+
+		stores = store.NewRedisStores(rdb)
+		b = broker.NewNATSBroker(nc, js)
+	default:
+		log.Fatalf("[MAIN] Unknown MODE %q, expected %s, %s or %s", mode, modeAPI, modeWorker, modeAll)
 	}
 
-	// Check if we should run as worker
-	if environment := os.Getenv("WORKER"); environment == "true" {
-		log.Printf("[MAIN] Starting as Worker...")
-		worker := worker.NewMatchmakeWorker(nc, rdb)
-		if err := worker.Start(); err != nil {
-			log.Fatalf("[MAIN] Failed to start worker: %v", err)
+	queues := loadQueues()
+
+	if mode == modeWorker || mode == modeAll {
+		queueNames := make([]string, len(queues))
+		for i, q := range queues {
+			queueNames[i] = q.Name
+		}
+		leases := lease.NewManager(stores.Leases, getEnv("REPLICA_ID", replicaID()), getEnvDuration("LEASE_TTL", lease.DefaultTTL), queueNames)
+		leases.Start()
+		defer leases.Stop()
+
+		log.Printf("[MAIN] Starting workers...")
+		for _, q := range queues {
+			worker := worker.NewMatchmakeWorker(b, stores, leases, q)
+			if err := worker.Start(); err != nil {
+				log.Fatalf("[MAIN] Failed to start worker for queue %s: %v", q.Name, err)
+			}
+			defer worker.Stop()
 		}
-		log.Printf("[MAIN] Worker started successfully, waiting for messages...")
+		log.Printf("[MAIN] Workers started successfully")
+	}
+
+	if mode == modeWorker {
+		log.Printf("[MAIN] Waiting for messages...")
 		<-ctx.Done()
+		stop()
+		log.Printf("[MAIN] Shutting down workers...")
 		return
 	}
 
@@ -91,15 +241,40 @@ func main() {
 	app := fiber.New()
 
 	log.Printf("[MAIN] Initializing Fiber app...")
-	matchmakerHandler := handler.NewMatchmakeHandler(nc, rdb)
+	notifier, err := notify.NewMatchNotifier(b)
+	if err != nil {
+		log.Fatalf("[MAIN] Failed to subscribe to match events: %v", err)
+	}
+	defer notifier.Close()
+
+	matchmakerHandler := handler.NewMatchmakeHandler(b, stores, notifier, queues)
+	matchesHandler := handler.NewMatchesHandler(stores)
 
 	log.Printf("[MAIN] Setting up API routes...")
 	app.Post("/matchmake", matchmakerHandler.Executer)
+	app.Use("/matchmake/ws", func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return fiber.ErrUpgradeRequired
+		}
+		return c.Next()
+	})
+	app.Get("/matchmake/ws", websocket.New(matchmakerHandler.WebSocket))
+	app.Get("/matchmake/:ticket", matchmakerHandler.GetTicket)
+	app.Get("/matchmake/:ticket/events", matchmakerHandler.TicketEvents)
+	app.Delete("/matchmake/:ticket", matchmakerHandler.CancelTicket)
+	app.Get("/matches/:id", matchesHandler.GetMatch)
+	app.Get("/matches/:id/delivery", matchesHandler.GetDelivery)
+	app.Get("/players/:id/matches", matchesHandler.ListPlayerMatches)
 	app.Get("/healthz", func(c *fiber.Ctx) error {
 		return c.SendStatus(fiber.StatusOK)
 	})
 
 	app.Get("/readyz", func(c *fiber.Ctx) error {
+		if rdb == nil {
+			// MODE=all has nothing external to check.
+			return c.SendStatus(fiber.StatusOK)
+		}
+
 		if err := rdb.Ping(ctx).Err(); err != nil {
 			log.Printf("[MAIN] Health check failed - Redis: %v", err)
 			return c.SendStatus(fiber.StatusServiceUnavailable)
@@ -113,11 +288,65 @@ func main() {
 		return c.SendStatus(fiber.StatusOK)
 	})
 
+	grpcServer := grpc.NewServer()
+	matchmakerHandler.RegisterGRPC(grpcServer)
+
+	grpcListener, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		log.Fatalf("[MAIN] Failed to listen for gRPC on port %s: %v", grpcPort, err)
+	}
+	go func() {
+		log.Printf("[MAIN] Starting gRPC server on port %s", grpcPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Printf("[MAIN] gRPC server stopped: %v", err)
+		}
+	}()
+	defer stopGRPC(grpcServer)
+
 	log.Printf("[MAIN] API routes configured successfully")
 	log.Printf("[MAIN] Starting HTTP server on port %s", appPort)
 	log.Printf("[MAIN] Health check available at /healthz")
 	log.Printf("[MAIN] Readiness check available at /readyz")
 	log.Printf("[MAIN] Matchmaking endpoint available at POST /matchmake")
+	log.Printf("[MAIN] Live matchmaking available at WebSocket /matchmake/ws")
+	log.Printf("[MAIN] Ticket endpoints available at GET/DELETE /matchmake/:ticket (GET supports ?wait= long-polling)")
+	log.Printf("[MAIN] Ticket events available as SSE at GET /matchmake/:ticket/events")
+	log.Printf("[MAIN] Match history available at GET /matches/:id and GET /players/:id/matches")
+	log.Printf("[MAIN] Webhook delivery status available at GET /matches/:id/delivery")
+	log.Printf("[MAIN] gRPC Matchmaker service available on port %s", grpcPort)
+
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+		stop()
+		log.Printf("[MAIN] Shutting down HTTP server...")
+		if err := app.ShutdownWithTimeout(shutdownTimeout); err != nil {
+			log.Printf("[MAIN] HTTP server shutdown failed: %v", err)
+		}
+	}()
+
+	if err := app.Listen(":" + appPort); err != nil {
+		log.Fatalf("[MAIN] HTTP server failed: %v", err)
+	}
+	// Listen returns once the listener closes; let open requests finish.
+	<-shutdown
+}
+
+// stopGRPC waits up to shutdownTimeout for in-flight calls, then closes the
+// ones left, such as WatchTicket streams.
+func stopGRPC(server *grpc.Server) {
+	log.Printf("[MAIN] Shutting down gRPC server...")
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
 
-	log.Fatal(app.Listen(":" + appPort))
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		log.Printf("[MAIN] gRPC calls still open after %s, closing them", shutdownTimeout)
+		server.Stop()
+	}
 }
@@ -0,0 +1,279 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             v6.32.0
+// source: match.proto
+
+package gen
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Matchmaker_Enqueue_FullMethodName     = "/matchmaker.Matchmaker/Enqueue"
+	Matchmaker_Cancel_FullMethodName      = "/matchmaker.Matchmaker/Cancel"
+	Matchmaker_GetTicket_FullMethodName   = "/matchmaker.Matchmaker/GetTicket"
+	Matchmaker_WatchTicket_FullMethodName = "/matchmaker.Matchmaker/WatchTicket"
+	Matchmaker_GetMatch_FullMethodName    = "/matchmaker.Matchmaker/GetMatch"
+)
+
+// MatchmakerClient is the client API for Matchmaker service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MatchmakerClient interface {
+	Enqueue(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*EnqueueResponse, error)
+	Cancel(ctx context.Context, in *TicketRequest, opts ...grpc.CallOption) (*Ticket, error)
+	GetTicket(ctx context.Context, in *TicketRequest, opts ...grpc.CallOption) (*TicketStatus, error)
+	// WatchTicket streams the ticket's events until it leaves the queue.
+	WatchTicket(ctx context.Context, in *TicketRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TicketEvent], error)
+	GetMatch(ctx context.Context, in *MatchLookup, opts ...grpc.CallOption) (*Match, error)
+}
+
+type matchmakerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMatchmakerClient(cc grpc.ClientConnInterface) MatchmakerClient {
+	return &matchmakerClient{cc}
+}
+
+func (c *matchmakerClient) Enqueue(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*EnqueueResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnqueueResponse)
+	err := c.cc.Invoke(ctx, Matchmaker_Enqueue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matchmakerClient) Cancel(ctx context.Context, in *TicketRequest, opts ...grpc.CallOption) (*Ticket, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ticket)
+	err := c.cc.Invoke(ctx, Matchmaker_Cancel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matchmakerClient) GetTicket(ctx context.Context, in *TicketRequest, opts ...grpc.CallOption) (*TicketStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TicketStatus)
+	err := c.cc.Invoke(ctx, Matchmaker_GetTicket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matchmakerClient) WatchTicket(ctx context.Context, in *TicketRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TicketEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Matchmaker_ServiceDesc.Streams[0], Matchmaker_WatchTicket_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TicketRequest, TicketEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Matchmaker_WatchTicketClient = grpc.ServerStreamingClient[TicketEvent]
+
+func (c *matchmakerClient) GetMatch(ctx context.Context, in *MatchLookup, opts ...grpc.CallOption) (*Match, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Match)
+	err := c.cc.Invoke(ctx, Matchmaker_GetMatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MatchmakerServer is the server API for Matchmaker service.
+// All implementations must embed UnimplementedMatchmakerServer
+// for forward compatibility.
+type MatchmakerServer interface {
+	Enqueue(context.Context, *MatchRequest) (*EnqueueResponse, error)
+	Cancel(context.Context, *TicketRequest) (*Ticket, error)
+	GetTicket(context.Context, *TicketRequest) (*TicketStatus, error)
+	// WatchTicket streams the ticket's events until it leaves the queue.
+	WatchTicket(*TicketRequest, grpc.ServerStreamingServer[TicketEvent]) error
+	GetMatch(context.Context, *MatchLookup) (*Match, error)
+	mustEmbedUnimplementedMatchmakerServer()
+}
+
+// UnimplementedMatchmakerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMatchmakerServer struct{}
+
+func (UnimplementedMatchmakerServer) Enqueue(context.Context, *MatchRequest) (*EnqueueResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Enqueue not implemented")
+}
+func (UnimplementedMatchmakerServer) Cancel(context.Context, *TicketRequest) (*Ticket, error) {
+	return nil, status.Error(codes.Unimplemented, "method Cancel not implemented")
+}
+func (UnimplementedMatchmakerServer) GetTicket(context.Context, *TicketRequest) (*TicketStatus, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTicket not implemented")
+}
+func (UnimplementedMatchmakerServer) WatchTicket(*TicketRequest, grpc.ServerStreamingServer[TicketEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchTicket not implemented")
+}
+func (UnimplementedMatchmakerServer) GetMatch(context.Context, *MatchLookup) (*Match, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetMatch not implemented")
+}
+func (UnimplementedMatchmakerServer) mustEmbedUnimplementedMatchmakerServer() {}
+func (UnimplementedMatchmakerServer) testEmbeddedByValue()                    {}
+
+// UnsafeMatchmakerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MatchmakerServer will
+// result in compilation errors.
+type UnsafeMatchmakerServer interface {
+	mustEmbedUnimplementedMatchmakerServer()
+}
+
+func RegisterMatchmakerServer(s grpc.ServiceRegistrar, srv MatchmakerServer) {
+	// If the following call panics, it indicates UnimplementedMatchmakerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Matchmaker_ServiceDesc, srv)
+}
+
+func _Matchmaker_Enqueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatchmakerServer).Enqueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Matchmaker_Enqueue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatchmakerServer).Enqueue(ctx, req.(*MatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Matchmaker_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatchmakerServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Matchmaker_Cancel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatchmakerServer).Cancel(ctx, req.(*TicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Matchmaker_GetTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatchmakerServer).GetTicket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Matchmaker_GetTicket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatchmakerServer).GetTicket(ctx, req.(*TicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Matchmaker_WatchTicket_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TicketRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MatchmakerServer).WatchTicket(m, &grpc.GenericServerStream[TicketRequest, TicketEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Matchmaker_WatchTicketServer = grpc.ServerStreamingServer[TicketEvent]
+
+func _Matchmaker_GetMatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MatchLookup)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatchmakerServer).GetMatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Matchmaker_GetMatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatchmakerServer).GetMatch(ctx, req.(*MatchLookup))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Matchmaker_ServiceDesc is the grpc.ServiceDesc for Matchmaker service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Matchmaker_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "matchmaker.Matchmaker",
+	HandlerType: (*MatchmakerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Enqueue",
+			Handler:    _Matchmaker_Enqueue_Handler,
+		},
+		{
+			MethodName: "Cancel",
+			Handler:    _Matchmaker_Cancel_Handler,
+		},
+		{
+			MethodName: "GetTicket",
+			Handler:    _Matchmaker_GetTicket_Handler,
+		},
+		{
+			MethodName: "GetMatch",
+			Handler:    _Matchmaker_GetMatch_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTicket",
+			Handler:       _Matchmaker_WatchTicket_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "match.proto",
+}